@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"go.xrstf.de/kubesort/pkg/diff"
+	"go.xrstf.de/kubesort/pkg/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// runCheck sorts each file on its own and prints every object that would
+// change, similar to `gofmt -l`. It exits with 1 if any file is not sorted
+// (errors exit with 2, see errorExitCode).
+func runCheck(files []string, config *types.Configuration) {
+	if !checkFiles(files, config, os.Stdout) {
+		os.Exit(1)
+	}
+}
+
+// checkFiles prints all problems to out and returns true if all files are
+// sorted.
+func checkFiles(files []string, config *types.Configuration, out io.Writer) bool {
+	sorted := true
+
+	for _, file := range files {
+		problems := checkFile(file, config)
+		for _, problem := range problems {
+			fmt.Fprintf(out, "%s: %s\n", file, problem)
+		}
+
+		if len(problems) > 0 {
+			sorted = false
+		}
+	}
+
+	return sorted
+}
+
+func checkFile(file string, config *types.Configuration) []string {
+	objects, objectSources := loadFiles([]string{file})
+
+	original := make([]*unstructured.Unstructured, len(objects))
+	for i, obj := range objects {
+		original[i] = obj.DeepCopy()
+	}
+
+	sorted := sortObjects(objects, objectSources, config)
+	problems := []string{}
+
+	if !sameOrder(original, sorted) {
+		problems = append(problems, "objects are not in canonical order")
+	}
+
+	for _, obj := range diff.Compare(original, sorted, diff.Options{}).Objects {
+		switch obj.Type {
+		case diff.Added:
+			problems = append(problems, fmt.Sprintf("%s would be added", obj.Key))
+		case diff.Removed:
+			problems = append(problems, fmt.Sprintf("%s would be removed", obj.Key))
+		case diff.Changed:
+			problems = append(problems, fmt.Sprintf("%s is not sorted", obj.Key))
+		}
+	}
+
+	return problems
+}
+
+// sameOrder compares the order of all objects that exist in both lists.
+func sameOrder(a, b []*unstructured.Unstructured) bool {
+	aKeys := keysOf(a)
+	bKeys := keysOf(b)
+
+	aKeys = slices.DeleteFunc(aKeys, func(key diff.ObjectKey) bool {
+		return !slices.Contains(bKeys, key)
+	})
+
+	bKeys = slices.DeleteFunc(bKeys, func(key diff.ObjectKey) bool {
+		return !slices.Contains(aKeys, key)
+	})
+
+	return slices.Equal(aKeys, bKeys)
+}
+
+func keysOf(objects []*unstructured.Unstructured) []diff.ObjectKey {
+	keys := make([]diff.ObjectKey, len(objects))
+	for i, obj := range objects {
+		keys[i] = diff.KeyOf(obj)
+	}
+
+	return keys
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.xrstf.de/kubesort/pkg/types"
+)
+
+func TestCheckFiles(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name: "sorted",
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`,
+		},
+		{
+			name: "formatting is ignored",
+			input: `
+kind: ConfigMap
+apiVersion: v1
+metadata: {name: a}
+data: {b: "2", a: "1"}
+`,
+		},
+		{
+			name: "order only",
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+`,
+			expected: []string{"objects are not in canonical order"},
+		},
+		{
+			name: "contents only",
+			input: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: a
+spec:
+  containers:
+    - name: b
+    - name: a
+`,
+			expected: []string{"v1 Pod a is not sorted"},
+		},
+	}
+
+	config, err := types.LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load default configuration: %v", err)
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := writeTestFile(t, tc.input)
+
+			var out bytes.Buffer
+			sorted := checkFiles([]string{filename}, config, &out)
+
+			if sorted != (len(tc.expected) == 0) {
+				t.Errorf("Expected sorted = %v, but got %v.", len(tc.expected) == 0, sorted)
+			}
+
+			problems := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				if line != "" {
+					problems = append(problems, strings.TrimPrefix(line, filename+": "))
+				}
+			}
+
+			if tc.expected == nil {
+				tc.expected = []string{}
+			}
+
+			if !cmp.Equal(tc.expected, problems) {
+				t.Fatalf("Unexpected problems:\n%s", cmp.Diff(tc.expected, problems))
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	return filename
+}
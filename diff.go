@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"go.xrstf.de/kubesort/pkg/diff"
+	"go.xrstf.de/kubesort/pkg/types"
+)
+
+// runDiff sorts both sides with the same configuration and prints a diff for
+// every object that differs. Like diff(1), it exits with 1 if there are any
+// differences and with 2 if an error occurred (see errorExitCode).
+func runDiff(args []string, config *types.Configuration, opts globalOptions) {
+	if len(args) != 2 {
+		fatal("Usage: kubesort diff OLD NEW")
+	}
+
+	var printResult func(diff.Result, io.Writer) error
+
+	if opts.diffRenames < 0 || opts.diffRenames > 1 {
+		fatal("Invalid --diff-renames, must be between 0 and 1.")
+	}
+
+	switch opts.diffFormat {
+	case "unified":
+		printResult = diff.Result.Print
+	case "json":
+		printResult = diff.Result.PrintJSON
+	default:
+		fatalf("Invalid --diff-format %q, must be unified or json.", opts.diffFormat)
+	}
+
+	oldObjects, _ := sortFiles(expandSources(args[:1], opts.exclude), config)
+	newObjects, _ := sortFiles(expandSources(args[1:], opts.exclude), config)
+
+	result := diff.Compare(oldObjects, newObjects, diff.Options{
+		RenameThreshold: opts.diffRenames,
+	})
+	if err := printResult(result, os.Stdout); err != nil {
+		fatalf("Failed to print diff: %v", err)
+	}
+
+	if result.HasChanges() {
+		os.Exit(1)
+	}
+}
@@ -6,11 +6,18 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/spf13/pflag"
 
+	"go.xrstf.de/kubesort/pkg/diff"
+	"go.xrstf.de/kubesort/pkg/duplicates"
+	"go.xrstf.de/kubesort/pkg/normalize"
+	"go.xrstf.de/kubesort/pkg/output"
+	"go.xrstf.de/kubesort/pkg/scope"
 	"go.xrstf.de/kubesort/pkg/sort"
 	"go.xrstf.de/kubesort/pkg/types"
 	"go.xrstf.de/kubesort/pkg/yaml"
@@ -26,6 +33,20 @@ var (
 	BuildDate   string // RFC3339 format ("2006-01-02T15:04:05Z07:00")
 )
 
+// errorExitCode is used when kubesort fails. The diff command and --check
+// use 2 (just like diff(1)), because 1 means that differences were found.
+var errorExitCode = 1
+
+func fatal(v ...any) {
+	log.Print(v...)
+	os.Exit(errorExitCode)
+}
+
+func fatalf(format string, v ...any) {
+	log.Printf(format, v...)
+	os.Exit(errorExitCode)
+}
+
 func printVersion() {
 	// handle empty values in case `go install` was used
 	if BuildCommit == "" {
@@ -43,19 +64,54 @@ func printVersion() {
 }
 
 type globalOptions struct {
-	flattenLists bool
-	version      bool
-	configFile   string
+	flattenLists   bool
+	version        bool
+	configFile     string
+	namespace      string
+	stripNamespace bool
+	duplicates     string
+	diffFormat     string
+	diffRenames    float64
+	check          bool
+	write          bool
+	exclude        []string
+	outputDir      string
+	outputTemplate string
+	prune          bool
+	output         string
+	preserve       bool
+	fieldOrder     bool
+	normalize      []string
 }
 
 func (o *globalOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVarP(&o.configFile, "config", "c", o.configFile, "Load configuration from this file")
+	fs.StringArrayVar(&o.exclude, "exclude", o.exclude, "Skip files and directories matching this pattern when searching directories and glob patterns (can be given multiple times)")
 	fs.BoolVarP(&o.flattenLists, "flatten", "f", o.flattenLists, "Unwrap List kinds into standalone objects")
+	fs.StringSliceVar(&o.normalize, "normalize", o.normalize, "Remove fields using these presets before sorting (live-cluster)")
+	fs.StringVarP(&o.namespace, "namespace", "n", o.namespace, "Set this namespace on all namespaced objects that have none")
+	fs.BoolVar(&o.stripNamespace, "strip-namespace", o.stripNamespace, "Remove the namespace (see --namespace) from all objects after sorting")
+	fs.StringVar(&o.duplicates, "duplicates", o.duplicates, "How to handle objects with the same GVK, namespace and name (error, warn, keep-first, keep-last or merge)")
+	fs.BoolVar(&o.check, "check", o.check, "Check that every file is already sorted instead of printing the sorted objects, exit with 1 if not and 2 on errors")
+	fs.BoolVarP(&o.write, "write", "w", o.write, "Sort every file on its own and write the result back to the file instead of printing it")
+	fs.BoolVar(&o.fieldOrder, "field-order", o.fieldOrder, "Order fields inside objects like in the Kubernetes API types instead of alphabetically")
+	fs.BoolVar(&o.preserve, "preserve-comments", o.preserve, "Keep comments, anchors, quoting and key order of the input when printing or writing YAML (list items are always indented under their key)")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Output format when printing the sorted objects (yaml, json, jsonl or list)")
+	fs.StringVar(&o.outputDir, "output-dir", o.outputDir, "Write every object into its own file inside this directory instead of printing them")
+	fs.StringVar(&o.outputTemplate, "output-template", o.outputTemplate, "Go template for the filenames inside the output directory")
+	fs.BoolVar(&o.prune, "prune", o.prune, "Delete all other YAML and JSON files from the output directory")
+	fs.StringVar(&o.diffFormat, "diff-format", o.diffFormat, "Output format for the diff command (unified or json), which exits with 1 if there are differences and 2 on errors")
+	fs.Float64Var(&o.diffRenames, "diff-renames", o.diffRenames, "Minimum similarity (0-1) for the diff command to pair removed and added objects as renamed (0 disables rename detection)")
 	fs.BoolVarP(&o.version, "version", "V", o.version, "Show version info and exit immediately")
 }
 
 func main() {
-	opts := globalOptions{}
+	opts := globalOptions{
+		diffFormat:     "unified",
+		diffRenames:    diff.DefaultRenameThreshold,
+		outputTemplate: output.DefaultTemplate,
+		output:         string(output.FormatYAML),
+	}
 
 	opts.AddFlags(pflag.CommandLine)
 	pflag.Parse()
@@ -67,54 +123,275 @@ func main() {
 
 	args := pflag.Args()
 	if len(args) == 0 {
-		log.Fatal("No input file(s) provided.")
+		fatal("No input file(s) provided.")
+	}
+
+	if args[0] == "diff" || opts.check {
+		errorExitCode = 2
 	}
 
 	config, err := types.LoadConfig(opts.configFile)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatalf("Failed to load config: %v", err)
 	}
 
-	allObjects := []*unstructured.Unstructured{}
+	// command line flags take precedence over the configuration file
+	if opts.flattenLists {
+		config.FlattenLists = true
+	}
 
-	for _, arg := range args {
-		objects, err := yaml.Decode(arg)
-		if err != nil {
-			log.Fatalf("Failed to load %q: %v", arg, err)
+	if opts.namespace != "" {
+		config.Namespace = opts.namespace
+	}
+
+	if opts.stripNamespace {
+		config.StripNamespace = true
+	}
+
+	if config.StripNamespace && config.Namespace == "" {
+		fatal("Cannot strip namespaces without a namespace (see --namespace).")
+	}
+
+	for _, name := range opts.normalize {
+		preset := normalize.Preset(name)
+		if err := preset.Validate(); err != nil {
+			fatalf("Invalid --normalize: %v", err)
+		}
+
+		if !slices.Contains(config.Normalize, preset) {
+			config.Normalize = append(config.Normalize, preset)
+		}
+	}
+
+	if opts.fieldOrder {
+		config.FieldOrder.Enabled = true
+	}
+
+	if opts.duplicates != "" {
+		config.Duplicates = duplicates.Policy(opts.duplicates)
+	}
+
+	if err := config.Duplicates.Validate(); err != nil {
+		fatalf("Invalid --duplicates: %v", err)
+	}
+
+	if args[0] == "diff" {
+		// these only affect how sorted objects are printed or written
+		if opts.check || opts.write || opts.outputDir != "" || opts.prune || opts.preserve ||
+			pflag.CommandLine.Changed("output") || pflag.CommandLine.Changed("output-template") {
+			fatal("The diff command cannot be combined with --check, --write, --output, --output-dir, --output-template, --prune or --preserve-comments.")
 		}
 
-		allObjects = append(allObjects, objects...)
+		runDiff(args[1:], config, opts)
+		return
+	}
+
+	if opts.outputDir != "" && (opts.check || opts.write) {
+		fatal("--output-dir cannot be combined with --check or --write.")
+	}
+
+	if opts.prune && opts.outputDir == "" {
+		fatal("--prune requires --output-dir.")
+	}
+
+	format := output.Format(opts.output)
+	if err := format.Validate(); err != nil {
+		fatalf("Invalid --output: %v", err)
+	}
+
+	if format != output.FormatYAML && (opts.outputDir != "" || opts.check || opts.write) {
+		fatal("--output can only be used when printing the sorted objects.")
+	}
+
+	if opts.preserve && format != output.FormatYAML {
+		fatal("--preserve-comments requires YAML output.")
+	}
+
+	if config.FieldOrder.Enabled && format != output.FormatYAML {
+		fatal("Field order requires YAML output.")
+	}
+
+	files := expandSources(args, opts.exclude)
+
+	if opts.check {
+		runCheck(files, config)
+		return
+	}
+
+	if opts.write {
+		runWrite(files, config, opts.preserve)
+		return
+	}
+
+	allObjects, objectSources := sortFiles(files, config)
+
+	if opts.outputDir != "" {
+		if err := output.WriteObjects(opts.outputDir, allObjects, output.Options{
+			FilenameTemplate: opts.outputTemplate,
+			Prune:            opts.prune,
+			Encode: func(objects []*unstructured.Unstructured) ([]byte, error) {
+				return objectSources.encode(objects, config, opts.preserve)
+			},
+		}); err != nil {
+			fatalf("Failed to write objects: %v", err)
+		}
+
+		return
+	}
+
+	var encoded []byte
+	if format == output.FormatYAML {
+		encoded, err = objectSources.encode(allObjects, config, opts.preserve)
+	} else {
+		encoded, err = output.Encode(allObjects, format)
+	}
+	if err != nil {
+		fatalf("Failed to encode objects: %v", err)
 	}
 
-	if opts.flattenLists || config.FlattenLists {
-		allObjects = flattenLists(allObjects)
+	if _, err := os.Stdout.Write(encoded); err != nil {
+		fatalf("Failed to print objects: %v", err)
 	}
+}
 
-	allObjects, err = sort.Objects(allObjects, config.ObjectRules)
+// expandSources resolves directories and glob patterns into files.
+func expandSources(args []string, excludes []string) []string {
+	files, err := yaml.ExpandSources(args, excludes)
 	if err != nil {
-		log.Fatalf("Failed to sort objects: %v", err)
+		fatalf("Failed to find input files: %v", err)
+	}
+
+	if len(files) == 0 {
+		fatal("No input files found.")
 	}
 
-	for _, obj := range allObjects {
-		encoded, err := yaml.Encode(obj)
+	return files
+}
+
+// sortFiles loads all objects from the given files and sorts them.
+func sortFiles(files []string, config *types.Configuration) ([]*unstructured.Unstructured, sources) {
+	objects, objectSources := loadFiles(files)
+
+	return sortObjects(objects, objectSources, config), objectSources
+}
+
+// sources remembers where each object was loaded from.
+type sources map[*unstructured.Unstructured]yaml.Document
+
+// encode encodes the objects as YAML. If preserve is set, the documents the
+// objects were loaded from are updated instead, keeping their comments.
+func (s sources) encode(objects []*unstructured.Unstructured, config *types.Configuration, preserve bool) ([]byte, error) {
+	if !preserve && !config.FieldOrder.Enabled {
+		return yaml.EncodeAll(objects)
+	}
+
+	documents := make([]yaml.Document, len(objects))
+	for i, obj := range objects {
+		if preserve {
+			documents[i] = s[obj]
+		}
+		documents[i].Object = obj
+	}
+
+	var reorder yaml.NodeFunc
+	if config.FieldOrder.Enabled {
+		reorder = config.FieldOrder.Apply
+	}
+
+	return yaml.EncodePreserving(documents, reorder)
+}
+
+func loadFiles(files []string) ([]*unstructured.Unstructured, sources) {
+	allObjects := []*unstructured.Unstructured{}
+	objectSources := sources{}
+
+	for _, arg := range files {
+		documents, err := yaml.Decode(arg)
 		if err != nil {
-			log.Fatalf("Failed to encode object: %v", err)
+			fatalf("Failed to load %q: %v", arg, err)
+		}
+
+		for _, doc := range documents {
+			allObjects = append(allObjects, doc.Object)
+			objectSources[doc.Object] = doc
 		}
+	}
+
+	return allObjects, objectSources
+}
+
+func sortObjects(allObjects []*unstructured.Unstructured, objectSources sources, config *types.Configuration) []*unstructured.Unstructured {
+	if config.FlattenLists {
+		allObjects = flattenLists(allObjects, objectSources)
+	}
+
+	normalizeRules, err := normalize.Rules(config.Normalize)
+	if err != nil {
+		fatalf("Failed to normalize objects: %v", err)
+	}
+
+	normalizeRules = append(normalizeRules, config.RemoveRules...)
+
+	if err := normalize.Objects(allObjects, normalizeRules); err != nil {
+		fatalf("Failed to normalize objects: %v", err)
+	}
+
+	if err := normalize.ReplaceObjects(allObjects, config.ReplaceRules); err != nil {
+		fatalf("Failed to normalize objects: %v", err)
+	}
+
+	objectRules := config.ObjectRules
 
-		fmt.Printf("---\n%s\n", string(encoded))
+	// derive rules for custom resources from CRDs in the input; these do not
+	// overlap with the default rules, but user-defined rules should still win
+	if !config.DisableCRDRules {
+		objectRules = append(sort.RulesFromCRDs(allObjects), objectRules...)
 	}
+
+	scopes := scope.NewResolver(allObjects, config.Scopes)
+
+	if config.Namespace != "" {
+		scopes.SetDefaultNamespace(allObjects, config.Namespace)
+	}
+
+	allObjects, err = duplicates.Handle(allObjects, config.Duplicates, func(obj *unstructured.Unstructured) string {
+		return objectSources[obj].String()
+	})
+	if err != nil {
+		fatalf("Failed to handle duplicate objects: %v", err)
+	}
+
+	allObjects, err = sort.Objects(allObjects, sort.Options{
+		Rules:  objectRules,
+		Order:  config.ObjectOrder,
+		Scopes: scopes,
+	})
+	if err != nil {
+		fatalf("Failed to sort objects: %v", err)
+	}
+
+	if config.StripNamespace {
+		scopes.StripNamespace(allObjects, config.Namespace)
+	}
+
+	return allObjects
 }
 
-func flattenLists(input []*unstructured.Unstructured) []*unstructured.Unstructured {
+func flattenLists(input []*unstructured.Unstructured, objectSources sources) []*unstructured.Unstructured {
 	result := []*unstructured.Unstructured{}
 
 	for i, obj := range input {
 		if isList(obj) {
+			index := 0
+
 			if err := obj.EachListItem(func(o kruntime.Object) error {
-				result = append(result, o.(*unstructured.Unstructured))
+				item := o.(*unstructured.Unstructured)
+				objectSources[item] = objectSources[obj].ListItem(item, index)
+				result = append(result, item)
+				index++
 				return nil
 			}); err != nil {
-				log.Fatalf("Failed to flatten list: %v", err)
+				fatalf("Failed to flatten list: %v", err)
 			}
 		} else {
 			result = append(result, input[i])
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestFlags(t *testing.T) {
+	testcases := []struct {
+		args              []string
+		expectedOutput    string
+		expectedOutputDir string
+	}{
+		{
+			args:           []string{"-o", "json", "file.yaml"},
+			expectedOutput: "json",
+		},
+		{
+			args:           []string{"--output", "jsonl", "file.yaml"},
+			expectedOutput: "jsonl",
+		},
+		{
+			args:              []string{"--output-dir", "json", "file.yaml"},
+			expectedOutput:    "yaml",
+			expectedOutputDir: "json",
+		},
+	}
+
+	for _, tc := range testcases {
+		opts := globalOptions{output: "yaml"}
+
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		opts.AddFlags(fs)
+
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatalf("Failed to parse %v: %v", tc.args, err)
+		}
+
+		if opts.output != tc.expectedOutput {
+			t.Errorf("%v: expected output %q, but got %q", tc.args, tc.expectedOutput, opts.output)
+		}
+
+		if opts.outputDir != tc.expectedOutputDir {
+			t.Errorf("%v: expected output dir %q, but got %q", tc.args, tc.expectedOutputDir, opts.outputDir)
+		}
+	}
+}
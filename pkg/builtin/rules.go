@@ -0,0 +1,200 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package builtin
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+	"go.xrstf.de/kubesort/pkg/selector"
+	"go.xrstf.de/kubesort/pkg/sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	objectMetaType = reflect.TypeOf(metav1.ObjectMeta{})
+
+	// orderedLists have a merge key, but their order is still relevant:
+	// init containers run one after another and webhooks are called in order.
+	orderedLists = []string{"initContainers", "webhooks"}
+
+	// listMapKeys complements the patchMergeKey tags with the +listMapKey
+	// markers from the Go types, which are only available as comments.
+	listMapKeys = map[string][]string{
+		"CertificateSigningRequestStatus.conditions": {"type"},
+		"Container.ports":                            {"containerPort", "protocol"},
+		"EphemeralContainerCommon.ports":             {"containerPort", "protocol"},
+		"PodSpec.topologySpreadConstraints":          {"topologyKey", "whenUnsatisfiable"},
+		"ResourceRequirements.claims":                {"name"},
+		"ServiceSpec.ports":                          {"port", "protocol"},
+		"ValidatingAdmissionPolicyStatus.conditions": {"type"},
+	}
+)
+
+// ObjectRules generates sorting rules for all built-in kinds, based on the
+// patchMergeKey struct tags in the Go types. Every list that Kubernetes
+// merges by key during strategic merge patches is sorted by that key.
+func ObjectRules() []sort.SortingRule {
+	type ruleKey struct {
+		kind string
+		path string
+		keys string
+	}
+
+	versions := map[ruleKey][]string{}
+
+	for gvk, objType := range Scheme.AllKnownTypes() {
+		if !isObjectType(gvk, objType) {
+			continue
+		}
+
+		for _, rule := range listRules(objType, nil, nil) {
+			key := ruleKey{
+				kind: gvk.Kind,
+				path: rule.path,
+				keys: strings.Join(rule.keys, ","),
+			}
+
+			versions[key] = append(versions[key], gvk.GroupVersion().String())
+		}
+	}
+
+	rules := make([]sort.SortingRule, 0, len(versions))
+	for key, apiVersions := range versions {
+		slices.Sort(apiVersions)
+
+		rules = append(rules, sort.SortingRule{
+			Selector: selector.Selector{
+				Kinds:       []string{key.kind},
+				APIVersions: apiVersions,
+			},
+			Path:  key.path,
+			ByKey: strings.Split(key.keys, ","),
+		})
+	}
+
+	// Go maps are not ordered, but the output should be stable
+	slices.SortFunc(rules, func(a, b sort.SortingRule) int {
+		if a.Kinds[0] != b.Kinds[0] {
+			return strings.Compare(a.Kinds[0], b.Kinds[0])
+		}
+
+		if a.Path != b.Path {
+			return strings.Compare(a.Path, b.Path)
+		}
+
+		return strings.Compare(a.APIVersions[0], b.APIVersions[0])
+	})
+
+	return rules
+}
+
+// isObjectType returns true for top-level objects, but not for lists,
+// options or other meta types that are also part of the scheme.
+func isObjectType(gvk schema.GroupVersionKind, objType reflect.Type) bool {
+	if gvk.Version == "__internal" || strings.HasSuffix(gvk.Kind, "List") {
+		return false
+	}
+
+	if objType.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < objType.NumField(); i++ {
+		if objType.Field(i).Type == objectMetaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type listRule struct {
+	path string
+	keys []string
+}
+
+func listRules(t reflect.Type, path jsonpath.Path, stack []reflect.Type) []listRule {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || slices.Contains(stack, t) {
+		return nil
+	}
+
+	stack = append(stack, t)
+	rules := []listRule{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		if inline {
+			rules = append(rules, listRules(field.Type, path, stack)...)
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		fieldPath := append(slices.Clone(path), jsonpath.KeyStep(name))
+		fieldType := field.Type
+
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			rules = append(rules, listRules(fieldType, fieldPath, stack)...)
+
+		case reflect.Slice:
+			keys := listMapKeys[t.Name()+"."+name]
+			if keys == nil {
+				if mergeKey := field.Tag.Get("patchMergeKey"); mergeKey != "" {
+					keys = []string{mergeKey}
+				}
+			}
+
+			if len(keys) > 0 && !slices.Contains(orderedLists, name) {
+				rules = append(rules, listRule{
+					path: fieldPath.String(),
+					keys: keys,
+				})
+			}
+
+			rules = append(rules, listRules(fieldType.Elem(), append(fieldPath, jsonpath.WildcardStep{}), stack)...)
+
+		case reflect.Map:
+			rules = append(rules, listRules(fieldType.Elem(), append(fieldPath, jsonpath.WildcardStep{}), stack)...)
+		}
+	}
+
+	return rules
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		// fields without JSON tags are embedded structs like TypeMeta
+		return field.Name, field.Anonymous
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+
+	return name, slices.Contains(strings.Split(options, ","), "inline")
+}
@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package builtin
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"go.xrstf.de/kubesort/pkg/sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestObjectRules(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "service ports are sorted by port and protocol",
+			input: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: dns
+spec:
+  ports:
+    - {name: metrics, port: 9153, protocol: TCP}
+    - {name: dns-udp, port: 53, protocol: UDP}
+    - {name: dns-tcp, port: 53, protocol: TCP}
+`,
+			expected: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: dns
+spec:
+  ports:
+    - {name: dns-tcp, port: 53, protocol: TCP}
+    - {name: dns-udp, port: 53, protocol: UDP}
+    - {name: metrics, port: 9153, protocol: TCP}
+`,
+		},
+		{
+			name: "init containers keep their order",
+			input: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  initContainers:
+    - {name: migrate, image: app}
+    - {name: await-db, image: busybox}
+  containers:
+    - {name: sidecar, image: proxy}
+    - {name: app, image: app}
+`,
+			expected: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  initContainers:
+    - {name: migrate, image: app}
+    - {name: await-db, image: busybox}
+  containers:
+    - {name: app, image: app}
+    - {name: sidecar, image: proxy}
+`,
+		},
+		{
+			name: "webhooks keep their order",
+			input: `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: hooks
+webhooks:
+  - name: z.example.com
+  - name: a.example.com
+`,
+			expected: `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: hooks
+webhooks:
+  - name: z.example.com
+  - name: a.example.com
+`,
+		},
+		{
+			name: "older API versions are sorted as well",
+			input: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - {name: upload, image: aws}
+            - {name: dump, image: postgres}
+`,
+			expected: `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - {name: dump, image: postgres}
+            - {name: upload, image: aws}
+`,
+		},
+	}
+
+	rules := ObjectRules()
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(tc.input), &obj.Object); err != nil {
+				t.Fatalf("Failed to decode input: %v", err)
+			}
+
+			expected := map[string]any{}
+			if err := yaml.Unmarshal([]byte(tc.expected), &expected); err != nil {
+				t.Fatalf("Failed to decode expected object: %v", err)
+			}
+
+			sorted, err := sort.Object(obj, rules)
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			if !cmp.Equal(expected, sorted.Object) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(expected, sorted.Object))
+			}
+		})
+	}
+}
+
+func TestObjectRulesGroupAPIVersions(t *testing.T) {
+	matches := 0
+
+	for _, rule := range ObjectRules() {
+		if rule.Kinds[0] == "CronJob" && rule.Path == "spec.jobTemplate.spec.template.spec.containers" {
+			matches++
+
+			if !slices.Equal(rule.APIVersions, []string{"batch/v1", "batch/v1beta1"}) {
+				t.Errorf("Expected the rule to cover batch/v1 and batch/v1beta1, but got %v.", rule.APIVersions)
+			}
+		}
+	}
+
+	if matches != 1 {
+		t.Fatalf("Expected exactly one rule for CronJob containers, but got %d.", matches)
+	}
+}
+
+// TestListMapKeys ensures that every entry in listMapKeys still refers to a
+// list field in the Go types, so typos and renames in k8s.io/api do not go
+// unnoticed.
+func TestListMapKeys(t *testing.T) {
+	types := map[string][]reflect.Type{}
+	for _, objType := range Scheme.AllKnownTypes() {
+		collectTypes(objType, types)
+	}
+
+	for entry, keys := range listMapKeys {
+		typeName, fieldName, ok := strings.Cut(entry, ".")
+		if !ok {
+			t.Errorf("Invalid entry %q, must be Type.field.", entry)
+			continue
+		}
+
+		candidates, exists := types[typeName]
+		if !exists {
+			t.Errorf("%s: no type %s found in the scheme.", entry, typeName)
+			continue
+		}
+
+		found := false
+
+		for _, candidate := range candidates {
+			field, exists := findField(candidate, fieldName)
+			if !exists || field.Type.Kind() != reflect.Slice {
+				continue
+			}
+
+			itemType := dereference(field.Type.Elem())
+
+			for _, key := range keys {
+				if _, exists := findField(itemType, key); !exists {
+					t.Errorf("%s: list items of type %s have no field %q.", entry, itemType.Name(), key)
+				}
+			}
+
+			found = true
+		}
+
+		if !found {
+			t.Errorf("%s: type %s has no list field %q.", entry, typeName, fieldName)
+		}
+	}
+}
+
+func collectTypes(t reflect.Type, types map[string][]reflect.Type) {
+	t = dereference(t)
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map:
+		collectTypes(t.Elem(), types)
+
+	case reflect.Struct:
+		for _, known := range types[t.Name()] {
+			if known == t {
+				return
+			}
+		}
+
+		types[t.Name()] = append(types[t.Name()], t)
+
+		for i := 0; i < t.NumField(); i++ {
+			collectTypes(t.Field(i).Type, types)
+		}
+	}
+}
+
+// findField finds the field by its JSON name, including inlined fields.
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		fieldName, inline := jsonFieldName(field)
+		if inline {
+			if inlined, exists := findField(dereference(field.Type), name); exists {
+				return inlined, true
+			}
+
+			continue
+		}
+
+		if fieldName == name {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func dereference(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package builtin
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// clusterScopedKinds lists all cluster-scoped kinds of the Kubernetes API
+// server, taken from its discovery data. This includes kinds that are not
+// part of the Scheme (like CRDs and APIServices).
+var clusterScopedKinds = map[schema.GroupKind]struct{}{
+	{Group: "", Kind: "ComponentStatus"}:                                              {},
+	{Group: "", Kind: "Namespace"}:                                                    {},
+	{Group: "", Kind: "Node"}:                                                         {},
+	{Group: "", Kind: "PersistentVolume"}:                                             {},
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:     {},
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicy"}:        {},
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingAdmissionPolicyBinding"}: {},
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}:   {},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:                 {},
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                             {},
+	{Group: "authentication.k8s.io", Kind: "SelfSubjectReview"}:                       {},
+	{Group: "authentication.k8s.io", Kind: "TokenReview"}:                             {},
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectAccessReview"}:                  {},
+	{Group: "authorization.k8s.io", Kind: "SelfSubjectRulesReview"}:                   {},
+	{Group: "authorization.k8s.io", Kind: "SubjectAccessReview"}:                      {},
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:                 {},
+	{Group: "certificates.k8s.io", Kind: "ClusterTrustBundle"}:                        {},
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                       {},
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:       {},
+	{Group: "internal.apiserver.k8s.io", Kind: "StorageVersion"}:                      {},
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                                {},
+	{Group: "networking.k8s.io", Kind: "IPAddress"}:                                   {},
+	{Group: "networking.k8s.io", Kind: "ServiceCIDR"}:                                 {},
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                      {},
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                      {},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                         {},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                  {},
+	{Group: "resource.k8s.io", Kind: "ResourceClass"}:                                 {},
+	{Group: "resource.k8s.io", Kind: "ResourceSlice"}:                                 {},
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                               {},
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                      {},
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                        {},
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                   {},
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                               {},
+	{Group: "storage.k8s.io", Kind: "VolumeAttributesClass"}:                          {},
+	{Group: "storagemigration.k8s.io", Kind: "StorageVersionMigration"}:               {},
+}
+
+// knownKinds contains all kinds from the Scheme; it must be computed lazily,
+// as the Scheme is only populated in init().
+var knownKinds = sync.OnceValue(func() map[schema.GroupKind]struct{} {
+	kinds := map[schema.GroupKind]struct{}{}
+	for gvk := range Scheme.AllKnownTypes() {
+		kinds[gvk.GroupKind()] = struct{}{}
+	}
+
+	return kinds
+})
+
+// IsClusterScoped returns whether the given kind is cluster-scoped. The
+// second return value is false if the kind is not a built-in kind.
+func IsClusterScoped(gk schema.GroupKind) (clusterScoped bool, known bool) {
+	if _, ok := clusterScopedKinds[gk]; ok {
+		return true, true
+	}
+
+	_, ok := knownKinds()[gk]
+
+	return false, ok
+}
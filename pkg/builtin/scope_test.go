@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package builtin
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestClusterScopedKinds ensures that every kind in the Scheme that is marked
+// as +genclient:nonNamespaced in k8s.io/api is listed in clusterScopedKinds,
+// so the table cannot silently drift when k8s.io/api is updated.
+func TestClusterScopedKinds(t *testing.T) {
+	// looking up every package on its own is slow, so find the module once
+	corePkg, err := build.Import("k8s.io/api/core/v1", ".", build.FindOnly)
+	if err != nil {
+		t.Fatalf("Failed to find k8s.io/api: %v", err)
+	}
+
+	moduleDir := filepath.Dir(filepath.Dir(corePkg.Dir))
+
+	// package path => type names
+	nonNamespaced := map[string]map[string]bool{}
+
+	for gvk, objType := range Scheme.AllKnownTypes() {
+		pkgPath := objType.PkgPath()
+		if !strings.HasPrefix(pkgPath, "k8s.io/api/") {
+			continue
+		}
+
+		if _, ok := nonNamespaced[pkgPath]; !ok {
+			types, err := nonNamespacedTypes(filepath.Join(moduleDir, strings.TrimPrefix(pkgPath, "k8s.io/api/")))
+			if err != nil {
+				t.Fatalf("Failed to parse %s: %v", pkgPath, err)
+			}
+
+			nonNamespaced[pkgPath] = types
+		}
+
+		if !nonNamespaced[pkgPath][objType.Name()] {
+			continue
+		}
+
+		if _, ok := clusterScopedKinds[gvk.GroupKind()]; !ok {
+			t.Errorf("%s is cluster-scoped, but missing in clusterScopedKinds.", gvk.GroupKind())
+		}
+	}
+}
+
+// nonNamespacedTypes returns the names of all types in the given package
+// directory that have a +genclient:nonNamespaced marker.
+func nonNamespacedTypes(dir string) (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	types := map[string]bool{}
+	fset := token.NewFileSet()
+
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		// markers are often separated from the doc comment by a blank line,
+		// so all comments between the previous declaration and the type count
+		previousEnd := f.Package
+
+		for _, decl := range f.Decls {
+			start, end := previousEnd, decl.Pos()
+			previousEnd = decl.End()
+
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE || len(genDecl.Specs) != 1 {
+				continue
+			}
+
+			for _, comment := range f.Comments {
+				if comment.Pos() > start && comment.End() < end && strings.Contains(comment.Text(), "+genclient:nonNamespaced") {
+					types[genDecl.Specs[0].(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+
+	return types, nil
+}
@@ -0,0 +1,253 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"go.xrstf.de/kubesort/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type ChangeType string
+
+const (
+	Added     ChangeType = "added"
+	Removed   ChangeType = "removed"
+	Changed   ChangeType = "changed"
+	Unchanged ChangeType = "unchanged"
+	// Renamed objects have a different name, but are similar enough to
+	// their old counterpart to be considered the same object.
+	Renamed ChangeType = "renamed"
+)
+
+// ObjectKey identifies an object on both sides of a diff.
+type ObjectKey struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+}
+
+func KeyOf(obj *unstructured.Unstructured) ObjectKey {
+	return ObjectKey{
+		GVK:       obj.GroupVersionKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+func (k ObjectKey) String() string {
+	name := k.Name
+	if k.Namespace != "" {
+		name = fmt.Sprintf("%s/%s", k.Namespace, name)
+	}
+
+	return fmt.Sprintf("%s %s %s", k.GVK.GroupVersion().String(), k.GVK.Kind, name)
+}
+
+// ObjectDiff compares a single object. Old is nil for added objects and New
+// is nil for removed ones. Key always identifies the new object, if there is
+// one.
+type ObjectDiff struct {
+	Key  ObjectKey
+	Type ChangeType
+	Old  *unstructured.Unstructured
+	New  *unstructured.Unstructured
+}
+
+type Result struct {
+	Objects []ObjectDiff
+}
+
+// DefaultRenameThreshold is high enough that unrelated objects which only
+// share boilerplate like Helm labels are not reported as renamed.
+const DefaultRenameThreshold = 0.9
+
+type Options struct {
+	// RenameThreshold is the minimum similarity (between 0 and 1) of a
+	// removed and an added object of the same kind and namespace to consider
+	// them renamed. Rename detection is disabled if this is 0.
+	RenameThreshold float64
+}
+
+// Compare pairs objects by their GVK, namespace and name. Both lists are
+// expected to be sorted the same way; the result follows the order of the
+// new objects, with removed objects following their old predecessor.
+func Compare(oldObjects, newObjects []*unstructured.Unstructured, opts Options) Result {
+	oldByKey := map[ObjectKey]*unstructured.Unstructured{}
+	for _, obj := range oldObjects {
+		oldByKey[KeyOf(obj)] = obj
+	}
+
+	newByKey := map[ObjectKey]*unstructured.Unstructured{}
+	for _, obj := range newObjects {
+		newByKey[KeyOf(obj)] = obj
+	}
+
+	renames := map[ObjectKey]*unstructured.Unstructured{}
+	if opts.RenameThreshold > 0 {
+		renames = detectRenames(oldObjects, newObjects, oldByKey, newByKey, opts.RenameThreshold)
+	}
+
+	renamed := map[*unstructured.Unstructured]struct{}{}
+	for _, oldObj := range renames {
+		renamed[oldObj] = struct{}{}
+	}
+
+	// removed objects are anchored to the last preceding object that still
+	// exists; the zero key anchors them to the very beginning
+	removedAfter := map[ObjectKey][]*unstructured.Unstructured{}
+	anchor := ObjectKey{}
+
+	for _, obj := range oldObjects {
+		key := KeyOf(obj)
+		if _, exists := newByKey[key]; exists {
+			anchor = key
+		} else if _, exists := renamed[obj]; !exists {
+			removedAfter[anchor] = append(removedAfter[anchor], obj)
+		}
+	}
+
+	result := Result{}
+
+	addRemoved := func(anchor ObjectKey) {
+		for _, obj := range removedAfter[anchor] {
+			result.Objects = append(result.Objects, ObjectDiff{
+				Key:  KeyOf(obj),
+				Type: Removed,
+				Old:  obj,
+			})
+		}
+	}
+
+	addRemoved(ObjectKey{})
+
+	for _, obj := range newObjects {
+		key := KeyOf(obj)
+		diff := ObjectDiff{
+			Key: key,
+			New: obj,
+		}
+
+		oldObj, exists := oldByKey[key]
+		renamedFrom, isRenamed := renames[key]
+
+		switch {
+		case isRenamed:
+			diff.Type = Renamed
+			diff.Old = renamedFrom
+		case !exists:
+			diff.Type = Added
+		case reflect.DeepEqual(oldObj.Object, obj.Object):
+			diff.Type = Unchanged
+			diff.Old = oldObj
+		default:
+			diff.Type = Changed
+			diff.Old = oldObj
+		}
+
+		result.Objects = append(result.Objects, diff)
+
+		if exists {
+			addRemoved(key)
+		}
+	}
+
+	return result
+}
+
+func (r Result) Count(changeType ChangeType) int {
+	count := 0
+	for _, obj := range r.Objects {
+		if obj.Type == changeType {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r Result) HasChanges() bool {
+	return r.Count(Unchanged) != len(r.Objects)
+}
+
+// Print writes a unified diff for every added, removed or changed object,
+// followed by a summary.
+func (r Result) Print(w io.Writer) error {
+	for _, obj := range r.Objects {
+		if obj.Type == Unchanged {
+			continue
+		}
+
+		oldLines, err := encodeLines(obj.Old)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", obj.Key, err)
+		}
+
+		newLines, err := encodeLines(obj.New)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", obj.Key, err)
+		}
+
+		fromFile := fmt.Sprintf("a: %s", obj.Key)
+		toFile := fmt.Sprintf("b: %s", obj.Key)
+		header := fmt.Sprintf("%s (%s)", obj.Key, obj.Type)
+
+		switch obj.Type {
+		case Added:
+			fromFile = "/dev/null"
+		case Removed:
+			toFile = "/dev/null"
+		case Renamed:
+			fromFile = fmt.Sprintf("a: %s", KeyOf(obj.Old))
+			header = fmt.Sprintf("%s (renamed from %s)", obj.Key, obj.Old.GetName())
+		}
+
+		diff := difflib.UnifiedDiff{
+			A:        oldLines,
+			B:        newLines,
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		}
+
+		fmt.Fprintf(w, "# %s\n", header)
+
+		if err := difflib.WriteUnifiedDiff(w, diff); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprintf(w, "%d object(s) added, %d removed, %d changed, %d renamed, %d unchanged.\n",
+		r.Count(Added),
+		r.Count(Removed),
+		r.Count(Changed),
+		r.Count(Renamed),
+		r.Count(Unchanged),
+	)
+
+	return err
+}
+
+func encodeLines(obj *unstructured.Unstructured) ([]string, error) {
+	if obj == nil {
+		return nil, nil
+	}
+
+	encoded, err := yaml.Encode(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return difflib.SplitLines(strings.TrimSuffix(string(encoded), "\n")), nil
+}
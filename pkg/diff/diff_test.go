@@ -0,0 +1,190 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newObject creates an object with the given top-level fields (like spec).
+func newObject(apiVersion, kind, namespace, name string, fields map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+func newConfigMap(namespace, name string, data map[string]any) *unstructured.Unstructured {
+	return newObject("v1", "ConfigMap", namespace, name, map[string]any{"data": data})
+}
+
+func TestCompare(t *testing.T) {
+	oldObjects := []*unstructured.Unstructured{
+		newConfigMap("", "a", map[string]any{"key": "removed"}),
+		newConfigMap("", "b", map[string]any{"key": "same"}),
+		newConfigMap("", "c", map[string]any{"key": "removed"}),
+		newConfigMap("", "d", map[string]any{"key": "old"}),
+	}
+
+	newObjects := []*unstructured.Unstructured{
+		newConfigMap("", "b", map[string]any{"key": "same"}),
+		newConfigMap("", "bb", map[string]any{"key": "added"}),
+		newConfigMap("", "d", map[string]any{"key": "new"}),
+	}
+
+	result := Compare(oldObjects, newObjects, Options{})
+
+	summary := []string{}
+	for _, obj := range result.Objects {
+		summary = append(summary, obj.Key.Name+":"+string(obj.Type))
+	}
+
+	expected := []string{"a:removed", "b:unchanged", "c:removed", "bb:added", "d:changed"}
+	if !cmp.Equal(expected, summary) {
+		t.Fatalf("Expected %v, but got %v", expected, summary)
+	}
+
+	if !result.HasChanges() {
+		t.Fatal("Result should have changes.")
+	}
+
+	var buf bytes.Buffer
+	if err := result.Print(&buf); err != nil {
+		t.Fatalf("Failed to print: %v", err)
+	}
+
+	output := buf.String()
+
+	for _, line := range []string{"-  key: old", "+  key: new", "1 object(s) added, 2 removed, 1 changed, 0 renamed, 1 unchanged."} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, but got:\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output, "v1 ConfigMap b (") {
+		t.Errorf("Unchanged objects should not be printed, but got:\n%s", output)
+	}
+}
+
+func TestCompareWithoutChanges(t *testing.T) {
+	objects := []*unstructured.Unstructured{newConfigMap("", "a", map[string]any{"key": "x"})}
+
+	if Compare(objects, objects, Options{}).HasChanges() {
+		t.Fatal("Identical objects should have no changes.")
+	}
+}
+
+func TestCompareRenames(t *testing.T) {
+	data := map[string]any{"a": "1", "b": "2", "c": "3", "d": "4"}
+	changedData := map[string]any{"a": "1", "b": "2", "c": "3", "d": "5"}
+	otherData := map[string]any{"x": "y"}
+
+	oldObjects := []*unstructured.Unstructured{
+		newConfigMap("default", "app-config-7f9c8", data),
+		newConfigMap("default", "other-1", otherData),
+	}
+
+	newObjects := []*unstructured.Unstructured{
+		newConfigMap("default", "app-config-a1b2c", changedData),
+		newConfigMap("default", "unrelated", map[string]any{"z": "z"}),
+	}
+
+	testcases := []struct {
+		threshold float64
+		expected  []string
+	}{
+		{
+			threshold: 0,
+			expected:  []string{"app-config-7f9c8:removed", "other-1:removed", "app-config-a1b2c:added", "unrelated:added"},
+		},
+		{
+			threshold: 0.7,
+			expected:  []string{"other-1:removed", "app-config-a1b2c:renamed", "unrelated:added"},
+		},
+		{
+			threshold: 0.95,
+			expected:  []string{"app-config-7f9c8:removed", "other-1:removed", "app-config-a1b2c:added", "unrelated:added"},
+		},
+	}
+
+	for _, tc := range testcases {
+		result := Compare(oldObjects, newObjects, Options{RenameThreshold: tc.threshold})
+
+		summary := []string{}
+		for _, obj := range result.Objects {
+			summary = append(summary, obj.Key.Name+":"+string(obj.Type))
+		}
+
+		if !cmp.Equal(tc.expected, summary) {
+			t.Errorf("Threshold %v: expected %v, but got %v", tc.threshold, tc.expected, summary)
+		}
+	}
+
+	report := Compare(oldObjects, newObjects, Options{RenameThreshold: 0.7}).Report()
+
+	renamed := report.Objects[1]
+	if renamed.OldName != "app-config-7f9c8" {
+		t.Errorf("Expected old name to be reported, but got %q.", renamed.OldName)
+	}
+
+	expectedFields := []FieldChange{
+		{Path: "data.d", Type: Changed, Old: "4", New: "5"},
+		{Path: "metadata.name", Type: Changed, Old: "app-config-7f9c8", New: "app-config-a1b2c"},
+	}
+
+	if !cmp.Equal(expectedFields, renamed.Fields) {
+		t.Errorf("Unexpected fields:\n%s", cmp.Diff(expectedFields, renamed.Fields))
+	}
+}
+
+func TestCompareUnrelatedHelmObjects(t *testing.T) {
+	deployment := func(name string, image string, port int64) *unstructured.Unstructured {
+		obj := newObject("apps/v1", "Deployment", "default", name, map[string]any{
+			"spec": map[string]any{
+				"replicas": int64(1),
+				"template": map[string]any{
+					"spec": map[string]any{
+						"containers": []any{
+							map[string]any{"name": name, "image": image, "ports": []any{map[string]any{"containerPort": port}}},
+						},
+					},
+				},
+			},
+		})
+
+		obj.SetLabels(map[string]string{
+			"app.kubernetes.io/instance":   "shop",
+			"app.kubernetes.io/managed-by": "Helm",
+			"app.kubernetes.io/part-of":    "shop",
+			"app.kubernetes.io/version":    "1.0.0",
+			"helm.sh/chart":                "shop-1.0.0",
+		})
+
+		return obj
+	}
+
+	oldObjects := []*unstructured.Unstructured{deployment("cart", "shop/cart:1.0", 8080)}
+	newObjects := []*unstructured.Unstructured{deployment("checkout", "shop/checkout:1.0", 9090)}
+
+	result := Compare(oldObjects, newObjects, Options{RenameThreshold: DefaultRenameThreshold})
+
+	summary := []string{}
+	for _, obj := range result.Objects {
+		summary = append(summary, obj.Key.Name+":"+string(obj.Type))
+	}
+
+	expected := []string{"cart:removed", "checkout:added"}
+	if !cmp.Equal(expected, summary) {
+		t.Fatalf("Expected %v, but got %v", expected, summary)
+	}
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"reflect"
+	"slices"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+)
+
+// FieldChange describes a single changed field. Old is not set for added
+// fields, New is not set for removed ones.
+type FieldChange struct {
+	Path string     `json:"path"`
+	Type ChangeType `json:"change"`
+	Old  any        `json:"old,omitempty"`
+	New  any        `json:"new,omitempty"`
+}
+
+// Fields compares both objects and returns the paths of all changed fields.
+// List items that all have a unique name are paired by it, so changing a
+// container's image results in `spec.template.spec.containers[?(@.name == "app")].image`
+// instead of relying on the item's position.
+func Fields(oldObj, newObj map[string]any) []FieldChange {
+	return compareFields(jsonpath.Path{}, oldObj, newObj)
+}
+
+func compareFields(path jsonpath.Path, oldValue, newValue any) []FieldChange {
+	switch oldAsserted := oldValue.(type) {
+	case map[string]any:
+		if newAsserted, ok := newValue.(map[string]any); ok {
+			return compareMaps(path, oldAsserted, newAsserted)
+		}
+
+	case []any:
+		if newAsserted, ok := newValue.([]any); ok {
+			return compareLists(path, oldAsserted, newAsserted)
+		}
+	}
+
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	return []FieldChange{{
+		Path: path.String(),
+		Type: Changed,
+		Old:  oldValue,
+		New:  newValue,
+	}}
+}
+
+func compareMaps(path jsonpath.Path, oldMap, newMap map[string]any) []FieldChange {
+	keys := []string{}
+	for key := range oldMap {
+		keys = append(keys, key)
+	}
+	for key := range newMap {
+		if _, exists := oldMap[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	changes := []FieldChange{}
+
+	for _, key := range keys {
+		fieldPath := appendStep(path, jsonpath.KeyStep(key))
+		oldField, oldExists := oldMap[key]
+		newField, newExists := newMap[key]
+
+		switch {
+		case !oldExists:
+			changes = append(changes, FieldChange{Path: fieldPath.String(), Type: Added, New: newField})
+		case !newExists:
+			changes = append(changes, FieldChange{Path: fieldPath.String(), Type: Removed, Old: oldField})
+		default:
+			changes = append(changes, compareFields(fieldPath, oldField, newField)...)
+		}
+	}
+
+	return changes
+}
+
+func compareLists(path jsonpath.Path, oldList, newList []any) []FieldChange {
+	oldNames, oldNamed := itemNames(oldList)
+	newNames, newNamed := itemNames(newList)
+
+	if oldNamed && newNamed {
+		return compareNamedLists(path, oldList, newList, oldNames, newNames)
+	}
+
+	changes := []FieldChange{}
+
+	for i := 0; i < max(len(oldList), len(newList)); i++ {
+		itemPath := appendStep(path, jsonpath.IndexStep(i))
+
+		switch {
+		case i >= len(oldList):
+			changes = append(changes, FieldChange{Path: itemPath.String(), Type: Added, New: newList[i]})
+		case i >= len(newList):
+			changes = append(changes, FieldChange{Path: itemPath.String(), Type: Removed, Old: oldList[i]})
+		default:
+			changes = append(changes, compareFields(itemPath, oldList[i], newList[i])...)
+		}
+	}
+
+	return changes
+}
+
+func compareNamedLists(path jsonpath.Path, oldList, newList []any, oldNames, newNames []string) []FieldChange {
+	itemPath := func(name string) jsonpath.Path {
+		return appendStep(path, jsonpath.EqualsFilterStep{
+			Path:  jsonpath.Path{jsonpath.KeyStep("name")},
+			Value: name,
+		})
+	}
+
+	changes := []FieldChange{}
+
+	for i, name := range oldNames {
+		idx := slices.Index(newNames, name)
+		if idx < 0 {
+			changes = append(changes, FieldChange{Path: itemPath(name).String(), Type: Removed, Old: oldList[i]})
+		} else {
+			changes = append(changes, compareFields(itemPath(name), oldList[i], newList[idx])...)
+		}
+	}
+
+	for i, name := range newNames {
+		if !slices.Contains(oldNames, name) {
+			changes = append(changes, FieldChange{Path: itemPath(name).String(), Type: Added, New: newList[i]})
+		}
+	}
+
+	return changes
+}
+
+// itemNames returns the names of all list items, if every item is an object
+// with a unique name.
+func itemNames(list []any) ([]string, bool) {
+	names := make([]string, 0, len(list))
+
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		name, ok := obj["name"].(string)
+		if !ok || slices.Contains(names, name) {
+			return nil, false
+		}
+
+		names = append(names, name)
+	}
+
+	return names, true
+}
+
+func appendStep(path jsonpath.Path, step jsonpath.Step) jsonpath.Path {
+	return append(slices.Clone(path), step)
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFields(t *testing.T) {
+	testcases := []struct {
+		name     string
+		old      map[string]any
+		new      map[string]any
+		expected []FieldChange
+	}{
+		{
+			name:     "no changes",
+			old:      map[string]any{"a": map[string]any{"b": []any{int64(1)}}},
+			new:      map[string]any{"a": map[string]any{"b": []any{int64(1)}}},
+			expected: []FieldChange{},
+		},
+		{
+			name: "nested fields",
+			old:  map[string]any{"a": map[string]any{"b": "x", "c": false}},
+			new:  map[string]any{"a": map[string]any{"b": "y", "d.e": true}},
+			expected: []FieldChange{
+				{Path: "a.b", Type: Changed, Old: "x", New: "y"},
+				{Path: "a.c", Type: Removed, Old: false},
+				{Path: `a["d.e"]`, Type: Added, New: true},
+			},
+		},
+		{
+			name: "lists are compared by index",
+			old:  map[string]any{"args": []any{"a", "b"}},
+			new:  map[string]any{"args": []any{"a", "c", "d"}},
+			expected: []FieldChange{
+				{Path: "args[1]", Type: Changed, Old: "b", New: "c"},
+				{Path: "args[2]", Type: Added, New: "d"},
+			},
+		},
+		{
+			name: "named items are paired by name",
+			old: map[string]any{"containers": []any{
+				map[string]any{"name": "a", "image": "a:1"},
+				map[string]any{"name": "b"},
+			}},
+			new: map[string]any{"containers": []any{
+				map[string]any{"name": "a", "image": "a:2"},
+				map[string]any{"name": "c"},
+			}},
+			expected: []FieldChange{
+				{Path: `containers[?(@.name == "a")].image`, Type: Changed, Old: "a:1", New: "a:2"},
+				{Path: `containers[?(@.name == "b")]`, Type: Removed, Old: map[string]any{"name": "b"}},
+				{Path: `containers[?(@.name == "c")]`, Type: Added, New: map[string]any{"name": "c"}},
+			},
+		},
+		{
+			name: "changed types",
+			old:  map[string]any{"a": map[string]any{"b": "c"}},
+			new:  map[string]any{"a": "b"},
+			expected: []FieldChange{
+				{Path: "a", Type: Changed, Old: map[string]any{"b": "c"}, New: "b"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Fields(tc.old, tc.new)
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(tc.expected, result))
+			}
+		})
+	}
+}
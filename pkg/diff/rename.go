@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type renameGroup struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+type renameCandidate struct {
+	oldObj     *unstructured.Unstructured
+	newObj     *unstructured.Unstructured
+	similarity float64
+}
+
+// detectRenames pairs removed and added objects of the same kind and
+// namespace, most similar pairs first. The result maps the new objects'
+// keys to their old counterparts.
+func detectRenames(oldObjects, newObjects []*unstructured.Unstructured, oldByKey, newByKey map[ObjectKey]*unstructured.Unstructured, threshold float64) map[ObjectKey]*unstructured.Unstructured {
+	// flattening objects is expensive, so it is done once per object and not
+	// for every pair
+	fields := map[*unstructured.Unstructured][]string{}
+
+	removed := map[renameGroup][]*unstructured.Unstructured{}
+	for _, obj := range oldObjects {
+		if _, exists := newByKey[KeyOf(obj)]; !exists {
+			group := renameGroup{gvk: obj.GroupVersionKind(), namespace: obj.GetNamespace()}
+			removed[group] = append(removed[group], obj)
+			fields[obj] = leafFields(obj)
+		}
+	}
+
+	candidates := []renameCandidate{}
+
+	for _, newObj := range newObjects {
+		if _, exists := oldByKey[KeyOf(newObj)]; exists {
+			continue
+		}
+
+		group := renameGroup{gvk: newObj.GroupVersionKind(), namespace: newObj.GetNamespace()}
+		if len(removed[group]) == 0 {
+			continue
+		}
+
+		newFields := leafFields(newObj)
+
+		for _, oldObj := range removed[group] {
+			if score := similarity(fields[oldObj], newFields); score >= threshold {
+				candidates = append(candidates, renameCandidate{
+					oldObj:     oldObj,
+					newObj:     newObj,
+					similarity: score,
+				})
+			}
+		}
+	}
+
+	// stable sort keeps the input order for equally similar candidates
+	slices.SortStableFunc(candidates, func(a, b renameCandidate) int {
+		return cmp.Compare(b.similarity, a.similarity)
+	})
+
+	renames := map[ObjectKey]*unstructured.Unstructured{}
+	paired := map[*unstructured.Unstructured]struct{}{}
+
+	for _, candidate := range candidates {
+		newKey := KeyOf(candidate.newObj)
+
+		if _, exists := renames[newKey]; exists {
+			continue
+		}
+
+		if _, exists := paired[candidate.oldObj]; exists {
+			continue
+		}
+
+		renames[newKey] = candidate.oldObj
+		paired[candidate.oldObj] = struct{}{}
+	}
+
+	return renames
+}
+
+// similarity compares the leaf fields of two objects and returns a value
+// between 0 (completely different) and 1 (identical).
+func similarity(aFields, bFields []string) float64 {
+	total := len(aFields) + len(bFields)
+	if total == 0 {
+		return 1
+	}
+
+	counts := map[string]int{}
+	for _, field := range aFields {
+		counts[field]++
+	}
+
+	common := 0
+	for _, field := range bFields {
+		if counts[field] > 0 {
+			counts[field]--
+			common++
+		}
+	}
+
+	return float64(2*common) / float64(total)
+}
+
+// leafFields returns all scalar fields as "path=value" strings, except for
+// those that identify the object.
+func leafFields(obj *unstructured.Unstructured) []string {
+	copied := obj.DeepCopy()
+	unstructured.RemoveNestedField(copied.Object, "apiVersion")
+	unstructured.RemoveNestedField(copied.Object, "kind")
+	unstructured.RemoveNestedField(copied.Object, "metadata", "name")
+	unstructured.RemoveNestedField(copied.Object, "metadata", "namespace")
+
+	fields := []string{}
+	collectLeafFields(jsonpath.Path{}, copied.Object, &fields)
+
+	return fields
+}
+
+func collectLeafFields(path jsonpath.Path, value any, fields *[]string) {
+	switch asserted := value.(type) {
+	case map[string]any:
+		for key, field := range asserted {
+			collectLeafFields(appendStep(path, jsonpath.KeyStep(key)), field, fields)
+		}
+
+	case []any:
+		for i, item := range asserted {
+			collectLeafFields(appendStep(path, jsonpath.IndexStep(i)), item, fields)
+		}
+
+	default:
+		encoded, _ := json.Marshal(value)
+		*fields = append(*fields, fmt.Sprintf("%s=%s", path, encoded))
+	}
+}
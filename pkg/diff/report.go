@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package diff
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Report is the machine-readable form of a Result.
+type Report struct {
+	Summary ReportSummary  `json:"summary"`
+	Objects []ReportObject `json:"objects"`
+}
+
+type ReportSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Renamed   int `json:"renamed"`
+	Unchanged int `json:"unchanged"`
+}
+
+type ReportObject struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Name       string        `json:"name"`
+	OldName    string        `json:"oldName,omitempty"`
+	Type       ChangeType    `json:"change"`
+	Fields     []FieldChange `json:"fields,omitempty"`
+}
+
+// Report lists all added, removed, changed and renamed objects; for changed
+// and renamed objects, it also lists all changed fields.
+func (r Result) Report() Report {
+	report := Report{
+		Summary: ReportSummary{
+			Added:     r.Count(Added),
+			Removed:   r.Count(Removed),
+			Changed:   r.Count(Changed),
+			Renamed:   r.Count(Renamed),
+			Unchanged: r.Count(Unchanged),
+		},
+		Objects: []ReportObject{},
+	}
+
+	for _, obj := range r.Objects {
+		if obj.Type == Unchanged {
+			continue
+		}
+
+		reportObj := ReportObject{
+			APIVersion: obj.Key.GVK.GroupVersion().String(),
+			Kind:       obj.Key.GVK.Kind,
+			Namespace:  obj.Key.Namespace,
+			Name:       obj.Key.Name,
+			Type:       obj.Type,
+		}
+
+		if obj.Type == Renamed {
+			reportObj.OldName = obj.Old.GetName()
+		}
+
+		if obj.Type == Changed || obj.Type == Renamed {
+			reportObj.Fields = Fields(obj.Old.Object, obj.New.Object)
+		}
+
+		report.Objects = append(report.Objects, reportObj)
+	}
+
+	return report
+}
+
+func (r Result) PrintJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r.Report())
+}
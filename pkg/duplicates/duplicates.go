@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package duplicates
+
+import (
+	"fmt"
+	"log"
+
+	"go.xrstf.de/kubesort/pkg/builtin"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// Policy decides what happens to objects with the same GVK, namespace and name.
+type Policy string
+
+const (
+	// ErrorPolicy fails when duplicates are found.
+	ErrorPolicy Policy = "error"
+	// WarnPolicy logs a warning and keeps all duplicates (the default).
+	WarnPolicy Policy = "warn"
+	// KeepFirstPolicy only keeps the first of all duplicates.
+	KeepFirstPolicy Policy = "keep-first"
+	// KeepLastPolicy only keeps the last of all duplicates.
+	KeepLastPolicy Policy = "keep-last"
+	// MergePolicy merges later duplicates onto the first one, using a
+	// strategic merge for built-in kinds. The first object is updated in
+	// place, so it keeps its identity (e.g. to look up where it came from).
+	MergePolicy Policy = "merge"
+)
+
+var policies = []Policy{
+	ErrorPolicy,
+	WarnPolicy,
+	KeepFirstPolicy,
+	KeepLastPolicy,
+	MergePolicy,
+}
+
+func (p Policy) Validate() error {
+	if p == "" {
+		return nil
+	}
+
+	for _, known := range policies {
+		if p == known {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown duplicate policy %q, must be one of %v", p, policies)
+}
+
+// DescribeFunc returns where an object came from, e.g. a filename.
+type DescribeFunc func(obj *unstructured.Unstructured) string
+
+type identity struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+func (i identity) String() string {
+	name := i.name
+	if i.namespace != "" {
+		name = fmt.Sprintf("%s/%s", i.namespace, name)
+	}
+
+	return fmt.Sprintf("%s %s", i.gvk.Kind, name)
+}
+
+func identify(obj *unstructured.Unstructured) identity {
+	return identity{
+		gvk:       obj.GroupVersionKind(),
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+	}
+}
+
+// Handle finds objects with the same GVK, namespace and name and applies
+// the policy to them. The order of the remaining objects is kept.
+func Handle(objects []*unstructured.Unstructured, policy Policy, describe DescribeFunc) ([]*unstructured.Unstructured, error) {
+	result := make([]*unstructured.Unstructured, 0, len(objects))
+	seen := map[identity]int{}
+
+	for _, obj := range objects {
+		id := identify(obj)
+
+		idx, exists := seen[id]
+		if !exists {
+			seen[id] = len(result)
+			result = append(result, obj)
+			continue
+		}
+
+		first := result[idx]
+
+		switch policy {
+		case ErrorPolicy:
+			return nil, fmt.Errorf("%s is defined multiple times: %s and %s", id, describe(first), describe(obj))
+
+		case "", WarnPolicy:
+			log.Printf("Warning: %s is defined multiple times: %s and %s", id, describe(first), describe(obj))
+			result = append(result, obj)
+
+		case KeepFirstPolicy:
+			// nop
+
+		case KeepLastPolicy:
+			result[idx] = obj
+
+		case MergePolicy:
+			merged, err := merge(first, obj)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge %s onto %s: %w", describe(obj), describe(first), err)
+			}
+
+			first.Object = merged
+
+		default:
+			return nil, fmt.Errorf("unknown duplicate policy %q", policy)
+		}
+	}
+
+	return result, nil
+}
+
+func merge(base, overlay *unstructured.Unstructured) (map[string]any, error) {
+	// custom resources have no Go types to derive the patch strategies from
+	dataStruct, err := builtin.Scheme.New(base.GroupVersionKind())
+	if err != nil {
+		return mergeMaps(base.Object, overlay.Object), nil
+	}
+
+	return strategicpatch.StrategicMergeMapPatch(base.Object, overlay.Object, dataStruct)
+}
+
+// mergeMaps merges the overlay onto the base, recursively for nested maps;
+// everything else (including lists) is replaced by the overlay's value.
+func mergeMaps(base, overlay map[string]any) map[string]any {
+	result := make(map[string]any, len(base))
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range overlay {
+		baseMap, baseOK := result[key].(map[string]any)
+		overlayMap, overlayOK := value.(map[string]any)
+
+		if baseOK && overlayOK {
+			result[key] = mergeMaps(baseMap, overlayMap)
+		} else {
+			result[key] = value
+		}
+	}
+
+	return result
+}
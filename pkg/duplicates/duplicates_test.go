@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package duplicates
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, name string, spec map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+
+	return obj
+}
+
+func TestHandle(t *testing.T) {
+	objects := func() []*unstructured.Unstructured {
+		return []*unstructured.Unstructured{
+			newObject("v1", "Service", "svc", map[string]any{
+				"ports": []any{map[string]any{"port": int64(80), "protocol": "TCP"}},
+			}),
+			newObject("example.com/v1", "Thing", "thing", map[string]any{
+				"a":    map[string]any{"b": int64(1)},
+				"list": []any{int64(1)},
+			}),
+			newObject("v1", "ConfigMap", "svc", nil),
+			newObject("v1", "Service", "svc", map[string]any{
+				"ports": []any{map[string]any{"port": int64(443), "protocol": "TCP"}},
+			}),
+			newObject("example.com/v1", "Thing", "thing", map[string]any{
+				"a":    map[string]any{"c": int64(2)},
+				"list": []any{int64(2)},
+			}),
+		}
+	}
+
+	describe := func(obj *unstructured.Unstructured) string {
+		return obj.GetName()
+	}
+
+	testcases := []struct {
+		policy   Policy
+		expected []*unstructured.Unstructured
+		invalid  bool
+	}{
+		{
+			policy:  ErrorPolicy,
+			invalid: true,
+		},
+		{
+			policy:   WarnPolicy,
+			expected: objects(),
+		},
+		{
+			policy: KeepFirstPolicy,
+			expected: func() []*unstructured.Unstructured {
+				all := objects()
+				return all[:3]
+			}(),
+		},
+		{
+			policy: KeepLastPolicy,
+			expected: func() []*unstructured.Unstructured {
+				all := objects()
+				return []*unstructured.Unstructured{all[3], all[4], all[2]}
+			}(),
+		},
+		{
+			policy: MergePolicy,
+			expected: []*unstructured.Unstructured{
+				newObject("v1", "Service", "svc", map[string]any{
+					"ports": []any{
+						map[string]any{"port": int64(443), "protocol": "TCP"},
+						map[string]any{"port": int64(80), "protocol": "TCP"},
+					},
+				}),
+				newObject("example.com/v1", "Thing", "thing", map[string]any{
+					"a":    map[string]any{"b": int64(1), "c": int64(2)},
+					"list": []any{int64(2)},
+				}),
+				newObject("v1", "ConfigMap", "svc", nil),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			input := objects()
+
+			result, err := Handle(input, tc.policy, describe)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to handle duplicates: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatal("Should have failed, but did not.")
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(tc.expected, result))
+			}
+
+			// merged objects must still be the first occurrences, so that
+			// their source can be found
+			if tc.policy == MergePolicy && (result[0] != input[0] || result[1] != input[1]) {
+				t.Fatal("Merged objects should replace the first occurrences in place.")
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package fieldorder
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"go.xrstf.de/kubesort/pkg/builtin"
+	"go.xrstf.de/kubesort/pkg/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultTopLevel is used if Order.TopLevel is empty.
+var DefaultTopLevel = []string{"apiVersion", "kind", "metadata", "spec", "data", "status"}
+
+// Order puts the fields inside objects into the order in which they are
+// declared in the Go types from k8s.io/api, which is how objects are usually
+// written by humans. Fields that are not part of the Go types, and all fields
+// of custom resources except for their metadata, follow in their existing
+// order.
+type Order struct {
+	Enabled bool `yaml:"enabled"`
+	// TopLevel are the fields that come first in every object, in this order.
+	TopLevel []string `yaml:"topLevel"`
+}
+
+func (o Order) Validate() error {
+	for i, field := range o.TopLevel {
+		if field == "" {
+			return fmt.Errorf("top-level field %d is empty", i+1)
+		}
+
+		if slices.Contains(o.TopLevel[:i], field) {
+			return fmt.Errorf("top-level field %q is listed multiple times", field)
+		}
+	}
+
+	return nil
+}
+
+// Apply reorders all mappings in the node, which represents the object.
+// Nodes for other objects than mappings are left alone.
+func (o Order) Apply(obj *unstructured.Unstructured, node *yamlv3.Node) {
+	if !o.Enabled || node.Kind != yamlv3.MappingNode {
+		return
+	}
+
+	topLevel := o.TopLevel
+	if len(topLevel) == 0 {
+		topLevel = DefaultTopLevel
+	}
+
+	reorderStruct(node, objectType(obj), topLevel)
+}
+
+// objectType returns the Go type for the object. For objects of unknown kinds
+// at least the metadata can be ordered.
+func objectType(obj *unstructured.Unstructured) reflect.Type {
+	if typed, err := builtin.Scheme.New(obj.GroupVersionKind()); err == nil {
+		return reflect.TypeOf(typed)
+	}
+
+	return reflect.TypeOf(metav1.PartialObjectMetadata{})
+}
+
+func reorder(node *yamlv3.Node, t reflect.Type) {
+	t = dereference(t)
+	if t == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		switch t.Kind() {
+		case reflect.Struct:
+			reorderStruct(node, t, nil)
+
+		case reflect.Map:
+			for i := 1; i < len(node.Content); i += 2 {
+				reorder(node.Content[i], t.Elem())
+			}
+		}
+
+	case yamlv3.SequenceNode:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, item := range node.Content {
+				reorder(item, t.Elem())
+			}
+		}
+	}
+}
+
+func reorderStruct(node *yamlv3.Node, t reflect.Type, first []string) {
+	fields := fieldsOf(t)
+	reorderMapping(node, fields, first)
+
+	types := map[string]reflect.Type{}
+	for _, field := range fields {
+		types[field.name] = field.typ
+	}
+
+	for i := 1; i < len(node.Content); i += 2 {
+		reorder(node.Content[i], types[node.Content[i-1].Value])
+	}
+}
+
+// reorderMapping sorts the key/value pairs of the mapping: merge keys first,
+// then the given fields, then all struct fields in declaration order, then
+// all remaining fields in their current order. The head comment of the
+// first key stays at the top of the mapping.
+func reorderMapping(node *yamlv3.Node, fields []field, first []string) {
+	order := slices.Clone(first)
+	for _, field := range fields {
+		if !slices.Contains(order, field.name) {
+			order = append(order, field.name)
+		}
+	}
+
+	rank := func(key *yamlv3.Node) int {
+		if key.Kind != yamlv3.ScalarNode {
+			return len(order)
+		}
+
+		if key.Value == yaml.MergeKey {
+			return -1
+		}
+
+		if idx := slices.Index(order, key.Value); idx >= 0 {
+			return idx
+		}
+
+		return len(order)
+	}
+
+	pairs := make([][2]*yamlv3.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yamlv3.Node{node.Content[i], node.Content[i+1]})
+	}
+
+	if len(pairs) == 0 {
+		return
+	}
+
+	// the comment above the mapping (like the head comment of a document) is
+	// attached to its first key, but belongs to whichever key comes first
+	firstKey := pairs[0][0]
+
+	slices.SortStableFunc(pairs, func(a, b [2]*yamlv3.Node) int {
+		return rank(a[0]) - rank(b[0])
+	})
+
+	if newFirstKey := pairs[0][0]; newFirstKey != firstKey && firstKey.HeadComment != "" {
+		if newFirstKey.HeadComment != "" {
+			newFirstKey.HeadComment = firstKey.HeadComment + "\n" + newFirstKey.HeadComment
+		} else {
+			newFirstKey.HeadComment = firstKey.HeadComment
+		}
+
+		firstKey.HeadComment = ""
+	}
+
+	content := make([]*yamlv3.Node, 0, len(node.Content))
+	for _, pair := range pairs {
+		content = append(content, pair[0], pair[1])
+	}
+
+	node.Content = content
+}
+
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+// fieldsOf returns the JSON fields of the struct type in declaration order,
+// including the fields of inlined structs.
+func fieldsOf(t reflect.Type) []field {
+	t = dereference(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := []field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if strings.Contains(options, "inline") || (structField.Anonymous && name == "") {
+			fields = append(fields, fieldsOf(structField.Type)...)
+			continue
+		}
+
+		if name == "" {
+			name = structField.Name
+		}
+
+		fields = append(fields, field{name: name, typ: structField.Type})
+	}
+
+	return fields
+}
+
+func dereference(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
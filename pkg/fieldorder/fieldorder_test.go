@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package fieldorder
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApply(t *testing.T) {
+	testcases := []struct {
+		name     string
+		order    Order
+		input    string
+		expected string
+	}{
+		{
+			name:  "disabled",
+			order: Order{},
+			input: `
+kind: ConfigMap
+apiVersion: v1
+`,
+			expected: `
+kind: ConfigMap
+apiVersion: v1
+`,
+		},
+		{
+			name:  "built-in kind",
+			order: Order{Enabled: true},
+			input: `
+spec:
+  template:
+    spec:
+      containers:
+        - env:
+            - value: x
+              name: A
+          image: app
+          name: app
+      custom: true
+      affinity: {}
+  replicas: 1
+kind: Deployment
+metadata:
+  labels: {}
+  name: app
+  namespace: default
+apiVersion: apps/v1
+`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  labels: {}
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app
+          env:
+            - name: A
+              value: x
+      affinity: {}
+      custom: true
+`,
+		},
+		{
+			name:  "custom resource and top-level order",
+			order: Order{Enabled: true, TopLevel: []string{"kind", "apiVersion", "spec"}},
+			input: `
+apiVersion: example.com/v1
+kind: Example
+metadata:
+  namespace: default
+  name: example
+spec:
+  z: 1
+  a: 2
+`,
+			expected: `
+kind: Example
+apiVersion: example.com/v1
+spec:
+  z: 1
+  a: 2
+metadata:
+  name: example
+  namespace: default
+`,
+		},
+		{
+			name:  "head comment stays at the top",
+			order: Order{Enabled: true},
+			input: `
+# top comment
+kind: ConfigMap
+# the API version
+apiVersion: v1
+data:
+  # comment for b
+  b: "2"
+`,
+			expected: `
+# top comment
+# the API version
+apiVersion: v1
+kind: ConfigMap
+data:
+  # comment for b
+  b: "2"
+`,
+		},
+		{
+			name:  "head comments of other keys move with their key",
+			order: Order{Enabled: true},
+			input: `
+# top comment
+apiVersion: v1
+# the data
+data: {}
+kind: ConfigMap
+`,
+			expected: `
+# top comment
+apiVersion: v1
+kind: ConfigMap
+# the data
+data: {}
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &yaml.Node{}
+			if err := yaml.Unmarshal([]byte(tc.input), node); err != nil {
+				t.Fatalf("Failed to parse input: %v", err)
+			}
+
+			obj := &unstructured.Unstructured{}
+			if err := node.Decode(&obj.Object); err != nil {
+				t.Fatalf("Failed to decode input: %v", err)
+			}
+
+			tc.order.Apply(obj, node.Content[0])
+
+			var buf strings.Builder
+
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+
+			if err := encoder.Encode(node); err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			if output := "\n" + buf.String(); output != tc.expected {
+				t.Fatalf("Expected\n%s\nbut got\n%s", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Order{TopLevel: []string{"kind", "kind"}}).Validate(); err == nil {
+		t.Error("Duplicate fields should be rejected.")
+	}
+
+	if err := (Order{TopLevel: []string{"kind", ""}}).Validate(); err == nil {
+		t.Error("Empty fields should be rejected.")
+	}
+}
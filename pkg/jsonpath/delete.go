@@ -36,6 +36,10 @@ func deleteInternal(dest any, path Path) (any, error) {
 	thisStep := path[0]
 	remainingSteps := path[1:]
 
+	if isRecursiveStep(thisStep) {
+		return deleteRecursive(dest, path)
+	}
+
 	foundKeyThings, foundValueThings, _, err := traverseStep(dest, thisStep)
 	if err != nil {
 		if errors.Is(err, errNoSuchKey) || errors.Is(err, errIndexOutOfBounds) {
@@ -95,7 +99,7 @@ func deleteInternal(dest any, path Path) (any, error) {
 			return dest, nil
 		}
 
-		foundsKeys, ok := foundKeyThings.([]string)
+		foundsKeys, ok := objectKeys(foundKeyThings)
 		if ok {
 			asObject, ok := dest.(map[string]any)
 			if !ok {
@@ -140,6 +144,73 @@ func deleteInternal(dest any, path Path) (any, error) {
 	}
 }
 
+// deleteRecursive applies the remaining steps at the current level and then
+// repeats the recursive descent for every child of the remaining value.
+func deleteRecursive(dest any, path Path) (any, error) {
+	remainingSteps := path[1:]
+	if len(remainingSteps) == 0 {
+		return nil, errors.New("recursive descent cannot be the last step")
+	}
+
+	if stepApplies(dest, remainingSteps[0]) {
+		deleted, err := deleteInternal(dest, remainingSteps)
+		if err != nil {
+			return nil, err
+		}
+
+		dest = deleted
+	}
+
+	switch asserted := dest.(type) {
+	case map[string]any:
+		for key, value := range asserted {
+			deleted, err := deleteRecursive(value, path)
+			if err != nil {
+				return nil, err
+			}
+
+			asserted[key] = deleted
+		}
+
+	case []any:
+		for i, value := range asserted {
+			deleted, err := deleteRecursive(value, path)
+			if err != nil {
+				return nil, err
+			}
+
+			asserted[i] = deleted
+		}
+	}
+
+	return dest, nil
+}
+
+// objectKeys converts the keys found by filter steps on objects (which are
+// []any for maps and []string for structs) into strings.
+func objectKeys(foundKeyThings any) ([]string, bool) {
+	switch keys := foundKeyThings.(type) {
+	case []string:
+		return keys, true
+
+	case []any:
+		result := make([]string, 0, len(keys))
+		for _, key := range keys {
+			asString, ok := key.(string)
+			if !ok {
+				return nil, false
+			}
+
+			result = append(result, asString)
+		}
+
+		return result, true
+
+	default:
+		return nil, false
+	}
+}
+
 func deleteFromLeaf(dest any, step Step, foundKeyThings any) (any, error) {
 	switch step.(type) {
 	case SingleStep:
@@ -166,8 +237,13 @@ func deleteFromLeaf(dest any, step Step, foundKeyThings any) (any, error) {
 		}
 
 	case FilterStep:
-		foundsKeys, ok := foundKeyThings.([]string)
+		foundsKeys, ok := objectKeys(foundKeyThings)
 		if ok {
+			// filters on nil values find nothing
+			if len(foundsKeys) == 0 {
+				return dest, nil
+			}
+
 			asObject, ok := dest.(map[string]any)
 			if !ok {
 				panic("ObjectStep should have errored on a non-object value.")
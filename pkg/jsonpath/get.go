@@ -16,7 +16,7 @@ func Get(value any, path Path) (any, error) {
 		return value, nil
 	}
 
-	if path.HasFilterSteps() {
+	if path.HasFilterSteps() || path.HasRecursiveSteps() {
 		return getFiltered(value, path)
 	}
 
@@ -39,9 +39,26 @@ func getSingle(value any, path Path) (any, error) {
 func getFiltered(value any, path Path) ([]any, error) {
 	currentLeafValues := []any{value}
 
-	for _, step := range path {
+	for i, step := range path {
 		newLeafValues := []any{}
 
+		if isRecursiveStep(step) {
+			if i == len(path)-1 {
+				return nil, errors.New("recursive descent cannot be the last step")
+			}
+
+			for _, val := range currentLeafValues {
+				for _, descendant := range descendants(val) {
+					if stepApplies(descendant, path[i+1]) {
+						newLeafValues = append(newLeafValues, descendant)
+					}
+				}
+			}
+
+			currentLeafValues = newLeafValues
+			continue
+		}
+
 		for _, val := range currentLeafValues {
 			_, result, _, err := traverseStep(val, step)
 			if err != nil {
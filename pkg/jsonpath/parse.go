@@ -0,0 +1,443 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes a syntax error in a path expression.
+type ParseError struct {
+	Path     string
+	Position int
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid path %q: %s at position %d", e.Path, e.Message, e.Position)
+}
+
+// Parse turns a path expression like
+//
+//	spec.containers[?(@.name == "app")].env[0]["some.key"]
+//
+// into a Path. Keys can be given in dot notation or quoted in brackets,
+// list items are selected by index, [*] (or [] for compatibility) selects
+// all items and [?(@.field == value)] selects all items with a matching
+// field. "..key" applies the following step at every level (recursive
+// descent). A leading "$" denotes the root, so a first key that starts with
+// "$" itself must be quoted (`["$schema"]`).
+func Parse(path string) (Path, error) {
+	p := &parser{input: path}
+
+	result, err := p.parsePath(true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+
+	return result, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) consume(prefix string) bool {
+	if strings.HasPrefix(p.input[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) skipSpaces() {
+	for !p.done() && p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{
+		Path:     p.input,
+		Position: p.pos,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// parsePath parses steps until the end of the input or until a character
+// that cannot continue a path (like an operator inside of a filter).
+func (p *parser) parsePath(root bool) (Path, error) {
+	path := Path{}
+
+	// optional leading "$" for the root element
+	if root && p.consume("$") && !p.done() && p.peek() != '.' && p.peek() != '[' {
+		return nil, p.errorf("expected \".\" or \"[\" after \"$\"")
+	}
+
+	// the first key does not need to be preceded by a dot
+	if root && !p.done() && p.peek() != '.' && p.peek() != '[' {
+		step, err := p.parseDotStep()
+		if err != nil {
+			return nil, err
+		}
+
+		path = append(path, step)
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case '.':
+			p.pos++
+
+			if p.consume(".") {
+				path = append(path, RecursiveDescentStep{})
+
+				// "..[0]" or "..[?(…)]" continue with a bracket step
+				if p.peek() == '[' {
+					continue
+				}
+			}
+
+			step, err := p.parseDotStep()
+			if err != nil {
+				return nil, err
+			}
+
+			path = append(path, step)
+
+		case '[':
+			step, err := p.parseBracketStep()
+			if err != nil {
+				return nil, err
+			}
+
+			path = append(path, step)
+
+		default:
+			return path, nil
+		}
+	}
+
+	return path, nil
+}
+
+func (p *parser) parseDotStep() (Step, error) {
+	if p.consume("*") {
+		return WildcardStep{}, nil
+	}
+
+	start := p.pos
+	for !p.done() && !isKeyTerminator(p.peek()) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		return nil, p.errorf("expected key")
+	}
+
+	return KeyStep(p.input[start:p.pos]), nil
+}
+
+func isKeyTerminator(c byte) bool {
+	switch c {
+	case '.', '[', ']', '"', '\'', ' ', '=', '!', ')':
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *parser) parseBracketStep() (Step, error) {
+	// consume the opening bracket
+	p.pos++
+
+	var step Step
+
+	switch c := p.peek(); {
+	// legacy syntax for "all items"
+	case c == ']':
+		step = WildcardStep{}
+
+	case c == '*':
+		p.pos++
+		step = WildcardStep{}
+
+	case c == '"' || c == '\'':
+		key, err := p.parseQuotedString()
+		if err != nil {
+			return nil, err
+		}
+
+		step = KeyStep(key)
+
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+			p.pos++
+		}
+
+		index, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid index")
+		}
+
+		step = IndexStep(index)
+
+	case c == '?':
+		p.pos++
+
+		filter, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+
+		step = filter
+
+	case c == '-':
+		return nil, p.errorf("negative indexes are not supported")
+
+	case c == 0:
+		return nil, p.errorf("unterminated bracket")
+
+	default:
+		return nil, p.errorf("expected index, quoted key, \"*\" or filter expression")
+	}
+
+	if !p.consume("]") {
+		if p.done() {
+			return nil, p.errorf("unterminated bracket")
+		}
+
+		return nil, p.errorf("expected \"]\"")
+	}
+
+	return step, nil
+}
+
+func (p *parser) parseQuotedString() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+
+	var sb strings.Builder
+
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+
+		switch c {
+		case quote:
+			return sb.String(), nil
+
+		case '\\':
+			if p.done() {
+				p.pos = start
+				return "", p.errorf("unterminated string")
+			}
+
+			sb.WriteByte(p.peek())
+			p.pos++
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	p.pos = start
+
+	return "", p.errorf("unterminated string")
+}
+
+// parseFilter parses "(@.path == value)", the leading "?" has already been
+// consumed.
+func (p *parser) parseFilter() (Step, error) {
+	if !p.consume("(") {
+		return nil, p.errorf("expected \"(\"")
+	}
+
+	p.skipSpaces()
+
+	if !p.consume("@") {
+		return nil, p.errorf("expected \"@\"")
+	}
+
+	start := p.pos
+
+	path, err := p.parsePath(false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, step := range path {
+		if _, ok := step.(SingleStep); !ok {
+			p.pos = start
+			return nil, p.errorf("filter expressions can only use keys and indexes")
+		}
+	}
+
+	p.skipSpaces()
+
+	filter := EqualsFilterStep{Path: path}
+
+	switch {
+	case p.consume("=="):
+	case p.consume("!="):
+		filter.Negate = true
+	default:
+		return nil, p.errorf("expected \"==\" or \"!=\"")
+	}
+
+	p.skipSpaces()
+
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Value = value
+
+	p.skipSpaces()
+
+	if !p.consume(")") {
+		return nil, p.errorf("expected \")\"")
+	}
+
+	return filter, nil
+}
+
+var numberLiteral = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?`)
+
+func (p *parser) parseLiteral() (any, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseQuotedString()
+
+	case p.consume("true"):
+		return true, nil
+
+	case p.consume("false"):
+		return false, nil
+
+	case p.consume("null"):
+		return nil, nil
+
+	default:
+		number := numberLiteral.FindString(p.input[p.pos:])
+		if number == "" {
+			return nil, p.errorf("expected string, number, boolean or null")
+		}
+
+		p.pos += len(number)
+
+		if strings.Contains(number, ".") {
+			return strconv.ParseFloat(number, 64)
+		}
+
+		return strconv.ParseInt(number, 10, 64)
+	}
+}
+
+// String returns the path in the syntax understood by Parse.
+func (p Path) String() string {
+	var sb strings.Builder
+
+	for i, step := range p {
+		// keys directly following the root or a recursive descent need no dot
+		first := i == 0 || isRecursiveStep(p[i-1])
+
+		switch s := step.(type) {
+		case KeyStep:
+			writeKey(&sb, string(s), first)
+		case IndexStep:
+			fmt.Fprintf(&sb, "[%d]", int(s))
+		case WildcardStep:
+			sb.WriteString("[*]")
+		case RecursiveDescentStep:
+			sb.WriteString("..")
+		case EqualsFilterStep:
+			sb.WriteString("[?(@")
+			for _, filterStep := range s.Path {
+				switch fs := filterStep.(type) {
+				case IndexStep:
+					fmt.Fprintf(&sb, "[%d]", int(fs))
+				default:
+					key, _ := fs.(SingleStep).ToKey()
+					writeKey(&sb, key, false)
+				}
+			}
+
+			op := "=="
+			if s.Negate {
+				op = "!="
+			}
+
+			fmt.Fprintf(&sb, " %s %s)]", op, formatLiteral(s.Value))
+		case SingleStep:
+			if index, ok := s.ToIndex(); ok {
+				fmt.Fprintf(&sb, "[%d]", index)
+			} else {
+				key, _ := s.ToKey()
+				writeKey(&sb, key, first)
+			}
+		default:
+			sb.WriteString("[?]")
+		}
+	}
+
+	return sb.String()
+}
+
+var bareKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func writeKey(sb *strings.Builder, key string, first bool) {
+	if !bareKey.MatchString(key) {
+		fmt.Fprintf(sb, "[%s]", quote(key))
+		return
+	}
+
+	if !first {
+		sb.WriteByte('.')
+	}
+
+	sb.WriteString(key)
+}
+
+func formatLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quote is the counterpart to parseQuotedString, which only knows escaped
+// quotes and backslashes.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
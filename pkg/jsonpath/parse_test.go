@@ -0,0 +1,378 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected Path
+		invalid  bool
+	}{
+		{
+			input:    "",
+			expected: Path{},
+		},
+		{
+			input:    "$",
+			expected: Path{},
+		},
+		{
+			input:    "foo",
+			expected: Path{KeyStep("foo")},
+		},
+		{
+			input:    "$.foo",
+			expected: Path{KeyStep("foo")},
+		},
+		{
+			input:    ".foo",
+			expected: Path{KeyStep("foo")},
+		},
+		{
+			input:    "spec.template.spec",
+			expected: Path{KeyStep("spec"), KeyStep("template"), KeyStep("spec")},
+		},
+		{
+			input:    "foo-bar/baz",
+			expected: Path{KeyStep("foo-bar/baz")},
+		},
+		{
+			// a leading "$" is the root, so keys starting with it must be quoted
+			input:   "$schema",
+			invalid: true,
+		},
+		{
+			input:    `["$schema"]`,
+			expected: Path{KeyStep("$schema")},
+		},
+		{
+			input:    "$.$schema",
+			expected: Path{KeyStep("$schema")},
+		},
+		{
+			input:    "spec.$ref",
+			expected: Path{KeyStep("spec"), KeyStep("$ref")},
+		},
+		{
+			input:    "containers[]",
+			expected: Path{KeyStep("containers"), WildcardStep{}},
+		},
+		{
+			input:    "containers[].env",
+			expected: Path{KeyStep("containers"), WildcardStep{}, KeyStep("env")},
+		},
+		{
+			input:    "containers[*].env",
+			expected: Path{KeyStep("containers"), WildcardStep{}, KeyStep("env")},
+		},
+		{
+			input:    "labels.*",
+			expected: Path{KeyStep("labels"), WildcardStep{}},
+		},
+		{
+			input:    "containers[0]",
+			expected: Path{KeyStep("containers"), IndexStep(0)},
+		},
+		{
+			input:    "[12][3]",
+			expected: Path{IndexStep(12), IndexStep(3)},
+		},
+		{
+			input:    `metadata.annotations["app.kubernetes.io/name"]`,
+			expected: Path{KeyStep("metadata"), KeyStep("annotations"), KeyStep("app.kubernetes.io/name")},
+		},
+		{
+			input:    `metadata.annotations['app.kubernetes.io/name'].foo`,
+			expected: Path{KeyStep("metadata"), KeyStep("annotations"), KeyStep("app.kubernetes.io/name"), KeyStep("foo")},
+		},
+		{
+			input:    `["with \"quotes\" and \\"]`,
+			expected: Path{KeyStep(`with "quotes" and \`)},
+		},
+		{
+			input:    `["0"]`,
+			expected: Path{KeyStep("0")},
+		},
+		{
+			input: `containers[?(@.name=="sidecar")].env`,
+			expected: Path{
+				KeyStep("containers"),
+				EqualsFilterStep{Path: Path{KeyStep("name")}, Value: "sidecar"},
+				KeyStep("env"),
+			},
+		},
+		{
+			input: `ports[?( @.port != 80 )]`,
+			expected: Path{
+				KeyStep("ports"),
+				EqualsFilterStep{Path: Path{KeyStep("port")}, Value: int64(80), Negate: true},
+			},
+		},
+		{
+			input: `items[?(@.a["b.c"][1] == 1.5)]`,
+			expected: Path{
+				KeyStep("items"),
+				EqualsFilterStep{Path: Path{KeyStep("a"), KeyStep("b.c"), IndexStep(1)}, Value: 1.5},
+			},
+		},
+		{
+			input: `items[?(@ == true)]`,
+			expected: Path{
+				KeyStep("items"),
+				EqualsFilterStep{Path: Path{}, Value: true},
+			},
+		},
+		{
+			input: `items[?(@.x == null)]`,
+			expected: Path{
+				KeyStep("items"),
+				EqualsFilterStep{Path: Path{KeyStep("x")}, Value: nil},
+			},
+		},
+		{
+			input:    "..env",
+			expected: Path{RecursiveDescentStep{}, KeyStep("env")},
+		},
+		{
+			input:    "spec..containers[*].env",
+			expected: Path{KeyStep("spec"), RecursiveDescentStep{}, KeyStep("containers"), WildcardStep{}, KeyStep("env")},
+		},
+		{
+			input:    `$..["a.b"]`,
+			expected: Path{RecursiveDescentStep{}, KeyStep("a.b")},
+		},
+		{
+			input: `..[?(@.name == "x")]`,
+			expected: Path{
+				RecursiveDescentStep{},
+				EqualsFilterStep{Path: Path{KeyStep("name")}, Value: "x"},
+			},
+		},
+
+		// invalid paths
+
+		{
+			input:   "foo..",
+			invalid: true,
+		},
+		{
+			input:   "foo...bar",
+			invalid: true,
+		},
+		{
+			input:   "foo.",
+			invalid: true,
+		},
+		{
+			input:   "foo[",
+			invalid: true,
+		},
+		{
+			input:   "foo[0",
+			invalid: true,
+		},
+		{
+			input:   "foo[-1]",
+			invalid: true,
+		},
+		{
+			input:   "foo[bar]",
+			invalid: true,
+		},
+		{
+			input:   `foo["bar]`,
+			invalid: true,
+		},
+		{
+			input:   "foo]",
+			invalid: true,
+		},
+		{
+			input:   "$foo",
+			invalid: true,
+		},
+		{
+			input:   `foo[?(@.name = "x")]`,
+			invalid: true,
+		},
+		{
+			input:   `foo[?(@.name == x)]`,
+			invalid: true,
+		},
+		{
+			input:   `foo[?(@.name == "x"]`,
+			invalid: true,
+		},
+		{
+			input:   `foo[?(@[*] == "x")]`,
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := Parse(tc.input)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to parse: %v", err)
+				}
+
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("Expected a ParseError, but got %T", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to parse path, but got: %#v", result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %#v, but got %#v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := Parse("spec.containers[foo]")
+	if err == nil {
+		t.Fatal("Should not have been able to parse path.")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, but got %T", err)
+	}
+
+	if parseErr.Position != 16 {
+		t.Fatalf("Expected error at position 16, but got %d (%v)", parseErr.Position, err)
+	}
+}
+
+func TestPathString(t *testing.T) {
+	testcases := []struct {
+		path     Path
+		expected string
+	}{
+		{
+			path:     Path{},
+			expected: "",
+		},
+		{
+			path:     Path{KeyStep("spec"), KeyStep("containers"), IndexStep(0), KeyStep("image")},
+			expected: "spec.containers[0].image",
+		},
+		{
+			path:     Path{KeyStep("metadata"), KeyStep("labels"), KeyStep("app.kubernetes.io/name")},
+			expected: `metadata.labels["app.kubernetes.io/name"]`,
+		},
+		{
+			path:     Path{KeyStep(`a"b`)},
+			expected: `["a\"b"]`,
+		},
+		{
+			path:     Path{KeyStep("rules"), WildcardStep{}, KeyStep("verbs")},
+			expected: "rules[*].verbs",
+		},
+		{
+			path:     Path{RecursiveDescentStep{}, KeyStep("env")},
+			expected: "..env",
+		},
+		{
+			path:     Path{KeyStep("spec"), RecursiveDescentStep{}, KeyStep("a.b"), RecursiveDescentStep{}, WildcardStep{}},
+			expected: `spec..["a.b"]..[*]`,
+		},
+		{
+			path: Path{
+				KeyStep("containers"),
+				EqualsFilterStep{Path: Path{KeyStep("name")}, Value: "app"},
+				KeyStep("ports"),
+				EqualsFilterStep{Path: Path{KeyStep("containerPort")}, Value: int64(80), Negate: true},
+			},
+			expected: `containers[?(@.name == "app")].ports[?(@.containerPort != 80)]`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.expected, func(t *testing.T) {
+			result := tc.path.String()
+			if result != tc.expected {
+				t.Fatalf("Expected %q, but got %q", tc.expected, result)
+			}
+
+			parsed, err := Parse(result)
+			if err != nil {
+				t.Fatalf("Failed to parse formatted path: %v", err)
+			}
+
+			if !cmp.Equal(tc.path, parsed) {
+				t.Fatalf("Expected %#v after round trip, but got %#v", tc.path, parsed)
+			}
+		})
+	}
+}
+
+func TestGetWithParsedFilters(t *testing.T) {
+	value := map[string]any{
+		"containers": []any{
+			map[string]any{"name": "app", "ports": []any{int64(80), int64(443)}},
+			map[string]any{"name": "sidecar", "ports": []any{int64(8080)}},
+			map[string]any{"image": "unnamed"},
+		},
+	}
+
+	testcases := []struct {
+		path     string
+		expected any
+	}{
+		{
+			path:     `containers[?(@.name == "sidecar")].ports`,
+			expected: []any{[]any{int64(8080)}},
+		},
+		{
+			path:     `containers[?(@.name != "sidecar")].ports[0]`,
+			expected: []any{int64(80)},
+		},
+		{
+			path:     `containers[?(@.ports[1] == 443)].name`,
+			expected: []any{"app"},
+		},
+		{
+			path:     `containers[*].name`,
+			expected: []any{"app", "sidecar"},
+		},
+		{
+			path:     `containers[1].name`,
+			expected: "sidecar",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := Parse(tc.path)
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+
+			result, err := Get(value, path)
+			if err != nil {
+				t.Fatalf("Failed to get value: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v (%T), but got %v (%T)", tc.expected, tc.expected, result, result)
+			}
+		})
+	}
+}
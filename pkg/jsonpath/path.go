@@ -3,14 +3,17 @@
 
 package jsonpath
 
-import "strconv"
+import (
+	"reflect"
+	"strconv"
+)
 
 type Path []Step
 
 func (p Path) IsValid() bool {
 	for _, s := range p {
 		switch s.(type) {
-		case SingleStep, FilterStep:
+		case SingleStep, FilterStep, RecursiveDescentStep:
 			continue
 		default:
 			return false
@@ -35,6 +38,21 @@ func isFilterStep(s Step) bool {
 	return ok
 }
 
+func (p Path) HasRecursiveSteps() bool {
+	for _, s := range p {
+		if isRecursiveStep(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRecursiveStep(s Step) bool {
+	_, ok := s.(RecursiveDescentStep)
+	return ok
+}
+
 type Step any
 
 type SingleStep interface {
@@ -81,6 +99,135 @@ type FilterStep interface {
 	Keep(key any, value any) (bool, error)
 }
 
+// WildcardStep selects all items of a list or all values of an object.
+type WildcardStep struct{}
+
+func (WildcardStep) Keep(key any, value any) (bool, error) {
+	return true, nil
+}
+
+// RecursiveDescentStep selects the current value and all of its descendants,
+// so that the following step is applied at every level (like ".." in
+// JSONPath). It cannot be the last step in a path.
+type RecursiveDescentStep struct{}
+
+// EqualsFilterStep selects all items whose value at Path equals Value (or
+// does not equal it, if Negate is set). Items where Path does not exist
+// never match.
+type EqualsFilterStep struct {
+	Path   Path
+	Value  any
+	Negate bool
+}
+
+func (f EqualsFilterStep) Keep(key any, value any) (bool, error) {
+	found, err := Get(value, f.Path)
+	if err != nil {
+		return false, nil
+	}
+
+	return valuesEqual(found, f.Value) != f.Negate, nil
+}
+
+func valuesEqual(a, b any) bool {
+	aNumber, aOK := ToFloat(a)
+	bNumber, bOK := ToFloat(b)
+
+	if aOK && bOK {
+		return aNumber == bNumber
+	}
+
+	switch a.(type) {
+	case nil, string, bool:
+		return a == b
+	default:
+		// objects and lists are never equal to the scalar literals in filters
+		return false
+	}
+}
+
+// ToFloat converts all numeric types that can occur in decoded JSON and
+// YAML documents to float64.
+func ToFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// stepApplies returns true if the step can be used to traverse into the
+// given value, so that recursive descents can skip over values that the
+// next step would otherwise fail on (like a key step on a list).
+func stepApplies(value any, step Step) bool {
+	if value == nil {
+		return false
+	}
+
+	rValue := reflect.Indirect(reflect.ValueOf(value))
+
+	switch rValue.Kind() {
+	case reflect.Map, reflect.Struct:
+		if _, ok := step.(FilterStep); ok {
+			return rValue.Kind() == reflect.Map
+		}
+
+		s, ok := step.(SingleStep)
+		if !ok {
+			return false
+		}
+
+		_, ok = s.ToKey()
+		return ok
+
+	case reflect.Slice, reflect.Array:
+		if _, ok := step.(FilterStep); ok {
+			return true
+		}
+
+		s, ok := step.(SingleStep)
+		if !ok {
+			return false
+		}
+
+		_, ok = s.ToIndex()
+		return ok
+
+	default:
+		return false
+	}
+}
+
+// descendants returns the value and all values nested in it, depth-first
+// and with object keys in alphabetical order.
+func descendants(value any) []any {
+	result := []any{value}
+
+	if !stepApplies(value, WildcardStep{}) {
+		return result
+	}
+
+	_, children, _, err := traverseStep(value, WildcardStep{})
+	if err != nil {
+		return result
+	}
+
+	for _, child := range children.([]any) {
+		result = append(result, descendants(child)...)
+	}
+
+	return result
+}
+
 func indexOrKey(s SingleStep) (*int, *string) {
 	index, ok := s.ToIndex()
 	if ok {
@@ -0,0 +1,171 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const recursiveTestDocument = `
+{
+  "spec": {
+    "env": ["b", "a"],
+    "containers": [
+      {"name": "one", "env": ["d", "c"]},
+      {"name": "two", "nested": {"env": ["f", "e"]}}
+    ],
+    "list": [["env"], "env"]
+  }
+}
+`
+
+func recursiveTestValue(t *testing.T) any {
+	var value any
+	if err := json.Unmarshal([]byte(recursiveTestDocument), &value); err != nil {
+		t.Fatalf("Invalid test document: %v", err)
+	}
+
+	return value
+}
+
+func TestGetRecursive(t *testing.T) {
+	testcases := []struct {
+		path     Path
+		expected any
+		invalid  bool
+	}{
+		{
+			path:     Path{RecursiveDescentStep{}, KeyStep("env")},
+			expected: []any{[]any{"b", "a"}, []any{"d", "c"}, []any{"f", "e"}},
+		},
+		{
+			path:     Path{KeyStep("spec"), KeyStep("containers"), RecursiveDescentStep{}, KeyStep("env")},
+			expected: []any{[]any{"d", "c"}, []any{"f", "e"}},
+		},
+		{
+			path:     Path{RecursiveDescentStep{}, KeyStep("env"), IndexStep(0)},
+			expected: []any{"b", "d", "f"},
+		},
+		{
+			path:     Path{RecursiveDescentStep{}, EqualsFilterStep{Path: Path{KeyStep("name")}, Value: "two"}, KeyStep("nested"), KeyStep("env")},
+			expected: []any{[]any{"f", "e"}},
+		},
+		{
+			path:     Path{RecursiveDescentStep{}, KeyStep("missing")},
+			expected: []any{},
+		},
+		{
+			path:    Path{KeyStep("spec"), RecursiveDescentStep{}},
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.path.String(), func(t *testing.T) {
+			result, err := Get(recursiveTestValue(t), tc.path)
+			if err != nil {
+				if !tc.invalid {
+					t.Fatalf("Failed to run: %v", err)
+				}
+
+				return
+			}
+
+			if tc.invalid {
+				t.Fatalf("Should not have been able to get value, but got: %v (%T)", result, result)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v (%T), but got %v (%T)", tc.expected, tc.expected, result, result)
+			}
+		})
+	}
+}
+
+func TestPatchRecursive(t *testing.T) {
+	path := Path{RecursiveDescentStep{}, KeyStep("env")}
+
+	result, err := Patch(recursiveTestValue(t), path, func(exists bool, key, val any) (any, error) {
+		list, ok := val.([]any)
+		if !ok {
+			return val, nil
+		}
+
+		return append(list, "new"), nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+
+	expected, err := Get(result, path)
+	if err != nil {
+		t.Fatalf("Failed to get patched values: %v", err)
+	}
+
+	want := []any{
+		[]any{"b", "a", "new"},
+		[]any{"d", "c", "new"},
+		[]any{"f", "e", "new"},
+	}
+
+	if !cmp.Equal(want, expected) {
+		t.Fatalf("Expected %v, but got %v", want, expected)
+	}
+
+	// the untouched list of lists must remain as it was
+	list, err := Get(result, Path{KeyStep("spec"), KeyStep("list")})
+	if err != nil {
+		t.Fatalf("Failed to get list: %v", err)
+	}
+
+	if !cmp.Equal([]any{[]any{"env"}, "env"}, list) {
+		t.Fatalf("List was modified: %v", list)
+	}
+}
+
+func TestDeleteRecursive(t *testing.T) {
+	result, err := Delete(recursiveTestValue(t), Path{RecursiveDescentStep{}, KeyStep("env")})
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+
+	var expected any
+	if err := json.Unmarshal([]byte(`
+{
+  "spec": {
+    "containers": [
+      {"name": "one"},
+      {"name": "two", "nested": {}}
+    ],
+    "list": [["env"], "env"]
+  }
+}
+`), &expected); err != nil {
+		t.Fatalf("Invalid expected value: %v", err)
+	}
+
+	if !cmp.Equal(expected, result) {
+		t.Fatalf("Expected %v, but got %v", expected, result)
+	}
+}
+
+func TestDeleteWildcardFromObject(t *testing.T) {
+	value := map[string]any{
+		"labels": map[string]any{"a": "1", "b": "2"},
+	}
+
+	result, err := Delete(value, Path{KeyStep("labels"), WildcardStep{}})
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+
+	expected := map[string]any{"labels": map[string]any{}}
+
+	if !cmp.Equal(expected, result) {
+		t.Fatalf("Expected %v, but got %v", expected, result)
+	}
+}
@@ -33,6 +33,10 @@ func patch(dest any, key any, exists bool, path Path, patchValue PatchFunc) (any
 	thisStep := path[0]
 	remainingSteps := path[1:]
 
+	if isRecursiveStep(thisStep) {
+		return patchRecursive(dest, key, exists, path, patchValue)
+	}
+
 	foundKeyThings, foundValueThings, destKind, err := traverseStep(dest, thisStep)
 	if err != nil && !errors.Is(err, errNoSuchKey) && !errors.Is(err, errIndexOutOfBounds) && !errors.Is(err, errPointerIsNil) {
 		return nil, err
@@ -153,6 +157,30 @@ func patch(dest any, key any, exists bool, path Path, patchValue PatchFunc) (any
 	}
 }
 
+// patchRecursive applies the remaining steps at the current level and then
+// repeats the recursive descent for every child of the (patched) value.
+func patchRecursive(dest any, key any, exists bool, path Path, patchValue PatchFunc) (any, error) {
+	remainingSteps := path[1:]
+	if len(remainingSteps) == 0 {
+		return nil, errors.New("recursive descent cannot be the last step")
+	}
+
+	if stepApplies(dest, remainingSteps[0]) {
+		patched, err := patch(dest, key, exists, remainingSteps, patchValue)
+		if err != nil {
+			return nil, err
+		}
+
+		dest = patched
+	}
+
+	if !stepApplies(dest, WildcardStep{}) {
+		return dest, nil
+	}
+
+	return patch(dest, key, exists, append(Path{WildcardStep{}}, path...), patchValue)
+}
+
 func patchFoundListItem(dest any, index int, existingValue any, existed bool, remainingSteps Path, patchValue PatchFunc) (any, error) {
 	if index < 0 {
 		panic(fmt.Sprintf("Found negative index %d in slice?", index))
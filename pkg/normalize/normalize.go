@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package normalize
+
+import (
+	"errors"
+	"fmt"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+	"go.xrstf.de/kubesort/pkg/selector"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Rule removes the field at Path from all matching objects.
+type Rule struct {
+	selector.Selector `yaml:",inline"`
+
+	Path string `yaml:"path"`
+}
+
+func (r Rule) Validate() error {
+	if r.Path == "" {
+		return errors.New("no path specified")
+	}
+
+	_, err := r.JSONPath()
+
+	return err
+}
+
+func (r Rule) JSONPath() (jsonpath.Path, error) {
+	return jsonpath.Parse(r.Path)
+}
+
+// Objects removes the fields of all matching rules from every object.
+func Objects(objects []*unstructured.Unstructured, rules []Rule) error {
+	for _, obj := range objects {
+		if err := Object(obj, rules); err != nil {
+			return fmt.Errorf("failed to normalize %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// Object removes the fields of all matching rules from the object. Maps that
+// are empty afterwards (like annotations that only contained the
+// last-applied-configuration) are removed as well.
+func Object(obj *unstructured.Unstructured, rules []Rule) error {
+	original := obj.DeepCopy().Object
+	data := any(obj.Object)
+
+	for _, rule := range rules {
+		if !rule.Matches(obj) {
+			continue
+		}
+
+		path, err := rule.JSONPath()
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", rule.Path, err)
+		}
+
+		data, err = jsonpath.Delete(data, path)
+		if err != nil {
+			return fmt.Errorf("failed to remove %s: %w", rule.Path, err)
+		}
+	}
+
+	normalized, ok := data.(map[string]any)
+	if !ok {
+		return errors.New("object is not a map anymore")
+	}
+
+	removeEmptiedMaps(original, normalized)
+	obj.Object = normalized
+
+	return nil
+}
+
+// removeEmptiedMaps removes all empty maps that were not empty before.
+func removeEmptiedMaps(before, after map[string]any) {
+	for key, value := range after {
+		afterMap, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		beforeMap, ok := before[key].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		removeEmptiedMaps(beforeMap, afterMap)
+
+		if len(afterMap) == 0 && len(beforeMap) > 0 {
+			delete(after, key)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package normalize
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObject(t *testing.T) {
+	liveCluster, err := Rules([]Preset{LiveClusterPreset})
+	if err != nil {
+		t.Fatalf("Failed to get preset rules: %v", err)
+	}
+
+	testcases := []struct {
+		name     string
+		rules    []Rule
+		input    map[string]any
+		expected map[string]any
+	}{
+		{
+			name:  "live cluster",
+			rules: liveCluster,
+			input: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]any{
+					"name":              "app",
+					"uid":               "abc",
+					"resourceVersion":   "123",
+					"generation":        int64(2),
+					"creationTimestamp": "2024-01-01T00:00:00Z",
+					"managedFields":     []any{map[string]any{"manager": "kubectl"}},
+					"annotations": map[string]any{
+						"kubectl.kubernetes.io/last-applied-configuration": "{}",
+						"deployment.kubernetes.io/revision":                "3",
+					},
+					"labels": map[string]any{},
+				},
+				"spec":   map[string]any{"replicas": int64(1)},
+				"status": map[string]any{"replicas": int64(1)},
+			},
+			expected: map[string]any{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]any{
+					"name":   "app",
+					"labels": map[string]any{},
+				},
+				"spec": map[string]any{"replicas": int64(1)},
+			},
+		},
+		{
+			name:  "kind-specific rules",
+			rules: liveCluster,
+			input: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name": "config",
+					"annotations": map[string]any{
+						"deployment.kubernetes.io/revision": "3",
+					},
+				},
+				"spec": map[string]any{"clusterIP": "10.0.0.1"},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]any{
+					"name": "config",
+					"annotations": map[string]any{
+						"deployment.kubernetes.io/revision": "3",
+					},
+				},
+				"spec": map[string]any{"clusterIP": "10.0.0.1"},
+			},
+		},
+		{
+			name:  "service cluster IPs",
+			rules: liveCluster,
+			input: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]any{"name": "app"},
+				"spec": map[string]any{
+					"clusterIP":  "10.0.0.1",
+					"clusterIPs": []any{"10.0.0.1"},
+					"ports":      []any{map[string]any{"port": int64(80)}},
+				},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]any{"name": "app"},
+				"spec": map[string]any{
+					"ports": []any{map[string]any{"port": int64(80)}},
+				},
+			},
+		},
+		{
+			name:  "headless services keep their cluster IP",
+			rules: liveCluster,
+			input: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]any{"name": "app"},
+				"spec": map[string]any{
+					"clusterIP":  "None",
+					"clusterIPs": []any{"None"},
+					"ports":      []any{map[string]any{"port": int64(80)}},
+				},
+			},
+			expected: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]any{"name": "app"},
+				"spec": map[string]any{
+					"clusterIP":  "None",
+					"clusterIPs": []any{"None"},
+					"ports":      []any{map[string]any{"port": int64(80)}},
+				},
+			},
+		},
+		{
+			name:  "recursive paths",
+			rules: []Rule{{Path: "..image"}},
+			input: map[string]any{
+				"containers": []any{
+					map[string]any{"name": "a", "image": "a"},
+					map[string]any{"name": "b", "image": "b"},
+				},
+			},
+			expected: map[string]any{
+				"containers": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tc.input}
+
+			if err := Object(obj, tc.rules); err != nil {
+				t.Fatalf("Failed to normalize: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, obj.Object) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(tc.expected, obj.Object))
+			}
+		})
+	}
+}
+
+func TestRulesWithUnknownPreset(t *testing.T) {
+	if _, err := Rules([]Preset{"unknown"}); err == nil {
+		t.Fatal("Expected an error, but got none.")
+	}
+}
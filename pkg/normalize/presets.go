@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package normalize
+
+import (
+	"fmt"
+
+	"go.xrstf.de/kubesort/pkg/selector"
+)
+
+// Preset is a named set of rules.
+type Preset string
+
+const (
+	// LiveClusterPreset removes all fields that the Kubernetes API server
+	// populates, so objects fetched from a cluster (e.g. via
+	// `kubectl get -o yaml`) can be compared to the manifests they were
+	// created from.
+	LiveClusterPreset Preset = "live-cluster"
+)
+
+var presets = map[Preset][]Rule{
+	LiveClusterPreset: {
+		{Path: "metadata.managedFields"},
+		{Path: "metadata.resourceVersion"},
+		{Path: "metadata.uid"},
+		{Path: "metadata.creationTimestamp"},
+		{Path: "metadata.generation"},
+		{Path: "metadata.selfLink"},
+		{Path: `metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`},
+		{Selector: selector.Selector{Kinds: []string{"Deployment"}}, Path: `metadata.annotations["deployment.kubernetes.io/revision"]`},
+		{Path: "status"},
+		// cluster IPs are assigned by the API server, except for headless
+		// Services ("None"); the filter selects spec (the only top-level field
+		// with a clusterIP), and clusterIPs must be removed first, as the
+		// filter cannot match anymore once clusterIP is gone
+		{Selector: selector.Selector{Kinds: []string{"Service"}}, Path: `[?(@.clusterIP != "None")].clusterIPs`},
+		{Selector: selector.Selector{Kinds: []string{"Service"}}, Path: `[?(@.clusterIP != "None")].clusterIP`},
+	},
+}
+
+func (p Preset) Validate() error {
+	if _, exists := presets[p]; !exists {
+		return fmt.Errorf("unknown preset %q, must be one of [%s]", p, LiveClusterPreset)
+	}
+
+	return nil
+}
+
+// Rules returns the rules of all presets.
+func Rules(presetNames []Preset) ([]Rule, error) {
+	rules := []Rule{}
+
+	for _, preset := range presetNames {
+		if err := preset.Validate(); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, presets[preset]...)
+	}
+
+	return rules, nil
+}
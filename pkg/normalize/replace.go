@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package normalize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// ReplaceRule replaces the field at Path in all matching objects, either
+// with a fixed Value or by replacing all matches of Regex in string values
+// with Replacement (which can refer to submatches like "${1}"). Fields that
+// do not exist are not created.
+type ReplaceRule struct {
+	Rule        `yaml:",inline"`
+	Value       any    `yaml:"value,omitempty"`
+	Regex       string `yaml:"regex,omitempty"`
+	Replacement string `yaml:"replacement,omitempty"`
+}
+
+func (r ReplaceRule) Validate() error {
+	if err := r.Rule.Validate(); err != nil {
+		return err
+	}
+
+	switch {
+	case r.Value == nil && r.Regex == "":
+		return errors.New("either value or regex must be specified")
+	case r.Value != nil && r.Regex != "":
+		return errors.New("cannot specify both value and regex")
+	case r.Value != nil && r.Replacement != "":
+		return errors.New("replacement can only be used with regex")
+	}
+
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+
+	if _, err := r.value(); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+
+	return nil
+}
+
+// value converts the value (as decoded from YAML) into the types used by
+// unstructured objects, e.g. int64 instead of int.
+func (r ReplaceRule) value() (any, error) {
+	if r.Value == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var converted any
+	if err := utiljson.Unmarshal(encoded, &converted); err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+// ReplaceObjects applies all matching rules to every object.
+func ReplaceObjects(objects []*unstructured.Unstructured, rules []ReplaceRule) error {
+	for _, obj := range objects {
+		if err := ReplaceObject(obj, rules); err != nil {
+			return fmt.Errorf("failed to replace fields in %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceObject applies all matching rules to the object.
+func ReplaceObject(obj *unstructured.Unstructured, rules []ReplaceRule) error {
+	data := any(obj.Object)
+
+	for _, rule := range rules {
+		if !rule.Matches(obj) {
+			continue
+		}
+
+		path, err := rule.JSONPath()
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", rule.Path, err)
+		}
+
+		replace, err := rule.replaceFunc()
+		if err != nil {
+			return err
+		}
+
+		data, err = jsonpath.Patch(data, path, func(exists bool, _ any, val any) (any, error) {
+			if !exists {
+				return nil, nil
+			}
+
+			return replace(val), nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to replace %s: %w", rule.Path, err)
+		}
+	}
+
+	replaced, ok := data.(map[string]any)
+	if !ok {
+		return errors.New("object is not a map anymore")
+	}
+
+	obj.Object = replaced
+
+	return nil
+}
+
+func (r ReplaceRule) replaceFunc() (func(any) any, error) {
+	if r.Regex == "" {
+		value, err := r.value()
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+
+		return func(any) any {
+			// every field needs its own copy
+			return runtime.DeepCopyJSONValue(value)
+		}, nil
+	}
+
+	regex, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+
+	return func(val any) any {
+		// only strings can be matched
+		str, ok := val.(string)
+		if !ok {
+			return val
+		}
+
+		return regex.ReplaceAllString(str, r.Replacement)
+	}, nil
+}
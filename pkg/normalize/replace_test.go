@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package normalize
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReplaceObject(t *testing.T) {
+	newDeployment := func(chart string, images ...string) map[string]any {
+		containers := []any{}
+		for _, image := range images {
+			containers = append(containers, map[string]any{"image": image})
+		}
+
+		return map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"labels": map[string]any{"helm.sh/chart": chart},
+			},
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": map[string]any{"containers": containers},
+				},
+			},
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		rules    string
+		input    map[string]any
+		expected map[string]any
+	}{
+		{
+			name: "fixed value",
+			rules: `
+- path: metadata.labels["helm.sh/chart"]
+  value: {masked: 1}
+`,
+			input: newDeployment("foo-1.2.3"),
+			expected: func() map[string]any {
+				obj := newDeployment("")
+				obj["metadata"].(map[string]any)["labels"].(map[string]any)["helm.sh/chart"] = map[string]any{"masked": int64(1)}
+				return obj
+			}(),
+		},
+		{
+			name: "regex with submatches",
+			rules: `
+- path: metadata.labels["helm.sh/chart"]
+  regex: '^(.+)-[0-9.]+$'
+  replacement: '${1}-x'
+`,
+			input:    newDeployment("foo-1.2.3"),
+			expected: newDeployment("foo-x"),
+		},
+		{
+			name: "all matching fields",
+			rules: `
+- kinds: [Deployment]
+  path: spec.template.spec.containers[*].image
+  regex: '@sha256:.+'
+`,
+			input:    newDeployment("foo", "app@sha256:abc", "sidecar:1.0"),
+			expected: newDeployment("foo", "app", "sidecar:1.0"),
+		},
+		{
+			name: "other kinds are ignored",
+			rules: `
+- kinds: [StatefulSet]
+  path: metadata.labels["helm.sh/chart"]
+  value: x
+`,
+			input:    newDeployment("foo"),
+			expected: newDeployment("foo"),
+		},
+		{
+			name: "missing fields are not created",
+			rules: `
+- path: metadata.annotations["checksum/config"]
+  value: x
+`,
+			input:    newDeployment("foo"),
+			expected: newDeployment("foo"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rules []ReplaceRule
+			if err := yaml.Unmarshal([]byte(tc.rules), &rules); err != nil {
+				t.Fatalf("Failed to parse rules: %v", err)
+			}
+
+			for _, rule := range rules {
+				if err := rule.Validate(); err != nil {
+					t.Fatalf("Invalid rule: %v", err)
+				}
+			}
+
+			obj := &unstructured.Unstructured{Object: tc.input}
+
+			if err := ReplaceObject(obj, rules); err != nil {
+				t.Fatalf("Failed to replace: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, obj.Object) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(tc.expected, obj.Object))
+			}
+		})
+	}
+}
+
+func TestReplaceRuleValidate(t *testing.T) {
+	testcases := []struct {
+		name  string
+		rule  ReplaceRule
+		valid bool
+	}{
+		{
+			name:  "value",
+			rule:  ReplaceRule{Rule: Rule{Path: "a"}, Value: "x"},
+			valid: true,
+		},
+		{
+			name:  "regex",
+			rule:  ReplaceRule{Rule: Rule{Path: "a"}, Regex: "x", Replacement: "y"},
+			valid: true,
+		},
+		{
+			name: "no path",
+			rule: ReplaceRule{Value: "x"},
+		},
+		{
+			name: "neither value nor regex",
+			rule: ReplaceRule{Rule: Rule{Path: "a"}},
+		},
+		{
+			name: "both value and regex",
+			rule: ReplaceRule{Rule: Rule{Path: "a"}, Value: "x", Regex: "y"},
+		},
+		{
+			name: "replacement without regex",
+			rule: ReplaceRule{Rule: Rule{Path: "a"}, Value: "x", Replacement: "y"},
+		},
+		{
+			name: "invalid regex",
+			rule: ReplaceRule{Rule: Rule{Path: "a"}, Regex: "("},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.rule.Validate(); (err == nil) != tc.valid {
+				t.Fatalf("Expected valid = %v, but got %v", tc.valid, err)
+			}
+		})
+	}
+}
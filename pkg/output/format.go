@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.xrstf.de/kubesort/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Format decides how the sorted objects are printed. All formats sort the
+// keys of every object alphabetically.
+type Format string
+
+const (
+	// FormatYAML is a multi-document YAML stream.
+	FormatYAML Format = "yaml"
+	// FormatJSON is a single JSON array containing all objects.
+	FormatJSON Format = "json"
+	// FormatJSONLines prints one compact JSON object per line.
+	FormatJSONLines Format = "jsonl"
+	// FormatList wraps all objects in a v1 List, encoded as YAML.
+	FormatList Format = "list"
+)
+
+func (f Format) Validate() error {
+	switch f {
+	case FormatYAML, FormatJSON, FormatJSONLines, FormatList:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q, must be yaml, json, jsonl or list", f)
+	}
+}
+
+func Encode(objects []*unstructured.Unstructured, format Format) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.EncodeAll(objects)
+
+	case FormatJSON:
+		items := make([]map[string]any, len(objects))
+		for i, obj := range objects {
+			items[i] = obj.Object
+		}
+
+		return encodeIndentedJSON(items)
+
+	case FormatJSONLines:
+		var buf bytes.Buffer
+
+		// the encoder terminates every object with a newline
+		encoder := newJSONEncoder(&buf)
+		for _, obj := range objects {
+			if err := encoder.Encode(obj.Object); err != nil {
+				return nil, err
+			}
+		}
+
+		return buf.Bytes(), nil
+
+	case FormatList:
+		items := make([]any, len(objects))
+		for i, obj := range objects {
+			items[i] = obj.Object
+		}
+
+		list := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}}
+
+		return yaml.EncodeAll([]*unstructured.Unstructured{list})
+
+	default:
+		return nil, format.Validate()
+	}
+}
+
+// EncodeJSONObject encodes a single object as indented JSON, just like the
+// items in FormatJSON.
+func EncodeJSONObject(obj *unstructured.Unstructured) ([]byte, error) {
+	return encodeIndentedJSON(obj.Object)
+}
+
+func encodeIndentedJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := newJSONEncoder(&buf)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// newJSONEncoder does not escape HTML characters, so values are printed
+// the same way in JSON and YAML.
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return encoder
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package output
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEncode(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newObject("v1", "ConfigMap", "default", "a"),
+		{Object: map[string]any{
+			"kind":       "ConfigMap",
+			"apiVersion": "v1",
+			"metadata":   map[string]any{"name": "b"},
+			"data":       map[string]any{"z": "<1>", "a": "2"},
+		}},
+	}
+
+	testcases := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: FormatYAML,
+			expected: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+
+---
+apiVersion: v1
+data:
+  a: "2"
+  z: <1>
+kind: ConfigMap
+metadata:
+  name: b
+
+`,
+		},
+		{
+			format: FormatJSON,
+			expected: `[
+  {
+    "apiVersion": "v1",
+    "kind": "ConfigMap",
+    "metadata": {
+      "name": "a",
+      "namespace": "default"
+    }
+  },
+  {
+    "apiVersion": "v1",
+    "data": {
+      "a": "2",
+      "z": "<1>"
+    },
+    "kind": "ConfigMap",
+    "metadata": {
+      "name": "b"
+    }
+  }
+]
+`,
+		},
+		{
+			format: FormatJSONLines,
+			expected: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a","namespace":"default"}}
+{"apiVersion":"v1","data":{"a":"2","z":"<1>"},"kind":"ConfigMap","metadata":{"name":"b"}}
+`,
+		},
+		{
+			format: FormatList,
+			expected: `---
+apiVersion: v1
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+    namespace: default
+- apiVersion: v1
+  data:
+    a: "2"
+    z: <1>
+  kind: ConfigMap
+  metadata:
+    name: b
+kind: List
+
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			encoded, err := Encode(objects, tc.format)
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			if string(encoded) != tc.expected {
+				t.Fatalf("Expected\n%s\nbut got\n%s", tc.expected, encoded)
+			}
+		})
+	}
+
+	if _, err := Encode(objects, "xml"); err == nil {
+		t.Fatal("Expected an error for an invalid format, but got none.")
+	}
+}
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"go.xrstf.de/kubesort/pkg/yaml"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultTemplate puts namespaced objects into one directory per namespace
+// and cluster-scoped objects into the root directory.
+const DefaultTemplate = "{{.Namespace}}/{{.Kind}}-{{.Name}}.yaml"
+
+type Options struct {
+	// FilenameTemplate is a Go template that is rendered for every object to
+	// determine its filename, relative to the output directory.
+	FilenameTemplate string
+	// Prune deletes all other manifest files (and directories that become
+	// empty) from the output directory.
+	Prune bool
+	// Encode is used to encode each object into its file. If not set,
+	// objects are encoded using yaml.EncodeAll.
+	Encode func(objects []*unstructured.Unstructured) ([]byte, error)
+}
+
+// templateData is available in filename templates.
+type templateData struct {
+	APIVersion string
+	Group      string
+	Version    string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("filename").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Filenames renders the template for every object. Objects that would end up
+// in the same file (compared case-insensitively, to be safe on all
+// filesystems) get a numeric suffix, so the first object keeps the plain
+// filename, the second gets "-2" and so on.
+func Filenames(objects []*unstructured.Unstructured, tpl *template.Template) ([]string, error) {
+	filenames := make([]string, len(objects))
+	taken := map[string]struct{}{}
+
+	for i, obj := range objects {
+		gvk := obj.GroupVersionKind()
+
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, templateData{
+			APIVersion: obj.GetAPIVersion(),
+			Group:      gvk.Group,
+			Version:    gvk.Version,
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to render filename for %s %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+
+		filename := filepath.Clean(strings.TrimLeft(buf.String(), "/"))
+		if !filepath.IsLocal(filename) {
+			return nil, fmt.Errorf("filename %q for %s %s is not inside the output directory", buf.String(), gvk.Kind, obj.GetName())
+		}
+
+		ext := filepath.Ext(filename)
+		base := strings.TrimSuffix(filename, ext)
+
+		for n := 2; ; n++ {
+			if _, exists := taken[strings.ToLower(filename)]; !exists {
+				break
+			}
+
+			filename = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+
+		taken[strings.ToLower(filename)] = struct{}{}
+		filenames[i] = filename
+	}
+
+	return filenames, nil
+}
+
+// WriteObjects writes every object into its own file inside the directory.
+// Files whose content would not change are not touched.
+func WriteObjects(directory string, objects []*unstructured.Unstructured, opts Options) error {
+	tpl, err := ParseTemplate(opts.FilenameTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid filename template: %w", err)
+	}
+
+	filenames, err := Filenames(objects, tpl)
+	if err != nil {
+		return err
+	}
+
+	encode := opts.Encode
+	if encode == nil {
+		encode = yaml.EncodeAll
+	}
+
+	written := map[string]struct{}{}
+
+	for i, obj := range objects {
+		filename := filepath.Join(directory, filenames[i])
+
+		encoded, err := encode([]*unstructured.Unstructured{obj})
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		if err := writeFile(filename, encoded); err != nil {
+			return err
+		}
+
+		written[filename] = struct{}{}
+	}
+
+	if opts.Prune {
+		if err := prune(directory, written); err != nil {
+			return fmt.Errorf("failed to prune %s: %w", directory, err)
+		}
+	}
+
+	return nil
+}
+
+func writeFile(filename string, content []byte) error {
+	current, err := os.ReadFile(filename)
+	if err == nil && bytes.Equal(current, content) {
+		return nil
+	}
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, content, 0644)
+}
+
+// prune removes all manifest files that were not written and all directories
+// that are empty afterwards. Hidden files and directories are left alone.
+func prune(directory string, written map[string]struct{}) error {
+	directories := []string{}
+
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == directory {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			directories = append(directories, path)
+			return nil
+		}
+
+		if _, exists := written[path]; exists || !yaml.IsManifestFile(path) {
+			return nil
+		}
+
+		return os.Remove(path)
+	})
+	if err != nil {
+		return err
+	}
+
+	// remove nested directories before their parents
+	slices.Reverse(directories)
+
+	for _, dir := range directories {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+func TestFilenames(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newObject("v1", "Namespace", "", "default"),
+		newObject("apps/v1", "Deployment", "default", "app"),
+		newObject("v1", "Service", "default", "app"),
+		newObject("v1", "ConfigMap", "default", "app"),
+		newObject("v1", "ConfigMap", "default", "App"),
+		newObject("v1", "ConfigMap", "other", "app"),
+	}
+
+	testcases := []struct {
+		template string
+		expected []string
+		invalid  bool
+	}{
+		{
+			template: DefaultTemplate,
+			expected: []string{
+				"Namespace-default.yaml",
+				"default/Deployment-app.yaml",
+				"default/Service-app.yaml",
+				"default/ConfigMap-app.yaml",
+				"default/ConfigMap-App-2.yaml",
+				"other/ConfigMap-app.yaml",
+			},
+		},
+		{
+			template: "{{ .Group | default }}",
+			invalid:  true,
+		},
+		{
+			template: "{{ .Name }}.yml",
+			expected: []string{
+				"default.yml",
+				"app.yml",
+				"app-2.yml",
+				"app-3.yml",
+				"App-4.yml",
+				"app-5.yml",
+			},
+		},
+		{
+			template: "{{ .Version }}/{{ .Kind | lower }}_{{ .Name }}",
+			expected: []string{
+				"v1/namespace_default",
+				"v1/deployment_app",
+				"v1/service_app",
+				"v1/configmap_app",
+				"v1/configmap_App-2",
+				"v1/configmap_app-3",
+			},
+		},
+		{
+			template: "../{{ .Name }}.yaml",
+			invalid:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.template, func(t *testing.T) {
+			tpl, err := ParseTemplate(tc.template)
+			if err == nil {
+				var filenames []string
+
+				filenames, err = Filenames(objects, tpl)
+				if err == nil {
+					for i, filename := range tc.expected {
+						tc.expected[i] = filepath.FromSlash(filename)
+					}
+
+					if !cmp.Equal(tc.expected, filenames) {
+						t.Fatalf("Unexpected filenames:\n%s", cmp.Diff(tc.expected, filenames))
+					}
+				}
+			}
+
+			if tc.invalid != (err != nil) {
+				t.Fatalf("Expected error = %v, but got %v", tc.invalid, err)
+			}
+		})
+	}
+}
+
+func TestWriteObjectsPrune(t *testing.T) {
+	directory := t.TempDir()
+
+	for _, file := range []string{"stale/ConfigMap-old.yaml", "keep.txt", ".git/config.yaml"} {
+		filename := filepath.Join(directory, file)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	objects := []*unstructured.Unstructured{
+		newObject("v1", "ConfigMap", "default", "new"),
+	}
+
+	if err := WriteObjects(directory, objects, Options{FilenameTemplate: DefaultTemplate, Prune: true}); err != nil {
+		t.Fatalf("Failed to write objects: %v", err)
+	}
+
+	files := []string{}
+	if err := filepath.WalkDir(directory, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			relPath, _ := filepath.Rel(directory, path)
+			files = append(files, filepath.ToSlash(relPath))
+		}
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(files)
+
+	expected := []string{".git/config.yaml", "default/ConfigMap-new.yaml", "keep.txt"}
+	if !cmp.Equal(expected, files) {
+		t.Fatalf("Unexpected files:\n%s", cmp.Diff(expected, files))
+	}
+
+	if _, err := os.Stat(filepath.Join(directory, "stale")); err == nil {
+		t.Error("Empty directory should have been removed.")
+	}
+}
@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package scope
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SetDefaultNamespace sets the namespace on all namespaced objects that have
+// none, like kubectl does when applying manifests.
+func (r *Resolver) SetDefaultNamespace(objects []*unstructured.Unstructured, namespace string) {
+	for _, obj := range objects {
+		if obj.GetNamespace() == "" && !r.IsClusterScoped(obj) {
+			obj.SetNamespace(namespace)
+		}
+	}
+}
+
+// StripNamespace removes the namespace from all namespaced objects that are
+// in it, so that manifests rendered with and without namespace compare equal.
+func (r *Resolver) StripNamespace(objects []*unstructured.Unstructured, namespace string) {
+	for _, obj := range objects {
+		if obj.GetNamespace() == namespace && !r.IsClusterScoped(obj) {
+			obj.SetNamespace("")
+		}
+	}
+}
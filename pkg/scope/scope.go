@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/builtin"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Scope uses the same values as the spec.scope field in CRDs.
+type Scope string
+
+const (
+	ClusterScope   Scope = "Cluster"
+	NamespaceScope Scope = "Namespaced"
+)
+
+func (s Scope) Validate() error {
+	switch s {
+	case ClusterScope, NamespaceScope:
+		return nil
+	default:
+		return fmt.Errorf("unknown scope %q, must be %q or %q", s, ClusterScope, NamespaceScope)
+	}
+}
+
+// Table maps kinds, optionally qualified with their API group
+// ("Certificate.cert-manager.io"), to their scope.
+type Table map[string]Scope
+
+func (t Table) Validate() error {
+	for kind, s := range t {
+		if kind == "" {
+			return errors.New("empty kind")
+		}
+
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("invalid scope for %s: %w", kind, err)
+		}
+	}
+
+	return nil
+}
+
+func (t Table) lookup(gk schema.GroupKind) (Scope, bool) {
+	if gk.Group != "" {
+		if s, ok := t[fmt.Sprintf("%s.%s", gk.Kind, gk.Group)]; ok {
+			return s, true
+		}
+	}
+
+	s, ok := t[gk.Kind]
+
+	return s, ok
+}
+
+// Resolver determines the scope of objects. Built-in kinds are looked up in
+// the built-in REST mapping, custom resources using the CRDs the Resolver
+// was created with and the user-provided table. Objects of unknown kinds are
+// considered cluster-scoped if they have no namespace.
+type Resolver struct {
+	crds  map[schema.GroupKind]Scope
+	table Table
+}
+
+// NewResolver learns the scopes of custom resources from all CRDs in the
+// given objects. CRDs without a valid scope are ignored with a warning.
+func NewResolver(objects []*unstructured.Unstructured, table Table) *Resolver {
+	crds := map[schema.GroupKind]Scope{}
+
+	for _, obj := range objects {
+		if obj.GetKind() != "CustomResourceDefinition" || !strings.HasPrefix(obj.GetAPIVersion(), "apiextensions.k8s.io/") {
+			continue
+		}
+
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		if group == "" || kind == "" {
+			continue
+		}
+
+		s, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+		if err := Scope(s).Validate(); err != nil {
+			log.Printf("Warning: ignoring invalid CustomResourceDefinition %s: %v", obj.GetName(), err)
+			continue
+		}
+
+		crds[schema.GroupKind{Group: group, Kind: kind}] = Scope(s)
+	}
+
+	return &Resolver{
+		crds:  crds,
+		table: table,
+	}
+}
+
+func (r *Resolver) IsClusterScoped(obj *unstructured.Unstructured) bool {
+	gk := obj.GroupVersionKind().GroupKind()
+
+	if clusterScoped, known := builtin.IsClusterScoped(gk); known {
+		return clusterScoped
+	}
+
+	if s, ok := r.crds[gk]; ok {
+		return s == ClusterScope
+	}
+
+	if s, ok := r.table.lookup(gk); ok {
+		return s == ClusterScope
+	}
+
+	return obj.GetNamespace() == ""
+}
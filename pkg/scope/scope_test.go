@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package scope
+
+import (
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+
+	return obj
+}
+
+func TestResolver(t *testing.T) {
+	crd := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "")
+	crd.Object["spec"] = map[string]any{
+		"group": "example.com",
+		"names": map[string]any{"kind": "Cluster"},
+		"scope": "Cluster",
+	}
+
+	resolver := NewResolver([]*unstructured.Unstructured{crd}, Table{
+		"Certificate.cert-manager.io": NamespaceScope,
+		"Widget":                      ClusterScope,
+	})
+
+	testcases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected bool
+	}{
+		{
+			name:     "namespaced built-in kind without namespace",
+			obj:      newObject("apps/v1", "Deployment", ""),
+			expected: false,
+		},
+		{
+			name:     "cluster-scoped built-in kind",
+			obj:      newObject("rbac.authorization.k8s.io/v1", "ClusterRole", ""),
+			expected: true,
+		},
+		{
+			name:     "built-in kind that is not part of the scheme",
+			obj:      newObject("apiregistration.k8s.io/v1", "APIService", ""),
+			expected: true,
+		},
+		{
+			name:     "CRD itself",
+			obj:      crd,
+			expected: true,
+		},
+		{
+			name:     "custom resource with CRD in the input",
+			obj:      newObject("example.com/v1", "Cluster", "accidentally-namespaced"),
+			expected: true,
+		},
+		{
+			name:     "kind from the table, qualified",
+			obj:      newObject("cert-manager.io/v1", "Certificate", ""),
+			expected: false,
+		},
+		{
+			name:     "kind from the table, unqualified",
+			obj:      newObject("example.com/v1", "Widget", "default"),
+			expected: true,
+		},
+		{
+			name:     "unknown kind without namespace",
+			obj:      newObject("example.com/v1", "Thing", ""),
+			expected: true,
+		},
+		{
+			name:     "unknown kind with namespace",
+			obj:      newObject("example.com/v1", "Thing", "default"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := resolver.IsClusterScoped(tc.obj); result != tc.expected {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewResolverInvalidScope(t *testing.T) {
+	invalid := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "")
+	invalid.Object["spec"] = map[string]any{
+		"group": "example.com",
+		"names": map[string]any{"kind": "Thing"},
+		"scope": "Global",
+	}
+
+	missing := newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "")
+	missing.Object["spec"] = map[string]any{
+		"group": "example.com",
+		"names": map[string]any{"kind": "Widget"},
+	}
+
+	resolver := NewResolver([]*unstructured.Unstructured{invalid, missing}, nil)
+
+	// both CRDs are ignored, so the namespace decides
+	for _, kind := range []string{"Thing", "Widget"} {
+		if !resolver.IsClusterScoped(newObject("example.com/v1", kind, "")) {
+			t.Errorf("Expected %s without namespace to be cluster-scoped.", kind)
+		}
+
+		if resolver.IsClusterScoped(newObject("example.com/v1", kind, "default")) {
+			t.Errorf("Expected %s with namespace to be namespaced.", kind)
+		}
+	}
+}
+
+func TestDefaultNamespace(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newObject("apps/v1", "Deployment", ""),
+		newObject("apps/v1", "Deployment", "other"),
+		newObject("apps/v1", "Deployment", "default"),
+		newObject("v1", "Namespace", ""),
+		newObject("example.com/v1", "Thing", ""),
+	}
+
+	resolver := NewResolver(objects, nil)
+
+	getNamespaces := func() []string {
+		result := []string{}
+		for _, obj := range objects {
+			result = append(result, obj.GetNamespace())
+		}
+		return result
+	}
+
+	resolver.SetDefaultNamespace(objects, "default")
+
+	expected := []string{"default", "other", "default", "", ""}
+	if namespaces := getNamespaces(); !slices.Equal(expected, namespaces) {
+		t.Fatalf("Expected %v, but got %v", expected, namespaces)
+	}
+
+	resolver.StripNamespace(objects, "default")
+
+	expected = []string{"", "other", "", "", ""}
+	if namespaces := getNamespaces(); !slices.Equal(expected, namespaces) {
+		t.Fatalf("Expected %v after stripping, but got %v", expected, namespaces)
+	}
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package selector
+
+import (
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Selector decides which objects a rule applies to. Empty lists match all
+// objects.
+type Selector struct {
+	Kinds       []string `yaml:"kinds,omitempty"`
+	APIVersions []string `yaml:"apiVersions,omitempty"`
+}
+
+func (s Selector) Matches(obj *unstructured.Unstructured) bool {
+	if len(s.APIVersions) > 0 && !slices.Contains(s.APIVersions, obj.GetAPIVersion()) {
+		return false
+	}
+
+	if len(s.Kinds) == 0 {
+		return true
+	}
+
+	return slices.Contains(s.Kinds, obj.GetKind())
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package selector
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMatches(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+
+	testcases := []struct {
+		name     string
+		selector Selector
+		expected bool
+	}{
+		{
+			name:     "empty selector",
+			expected: true,
+		},
+		{
+			name:     "matching kind",
+			selector: Selector{Kinds: []string{"StatefulSet", "Deployment"}},
+			expected: true,
+		},
+		{
+			name:     "other kind",
+			selector: Selector{Kinds: []string{"StatefulSet"}},
+		},
+		{
+			name:     "matching kind and API version",
+			selector: Selector{Kinds: []string{"Deployment"}, APIVersions: []string{"apps/v1"}},
+			expected: true,
+		},
+		{
+			name:     "other API version",
+			selector: Selector{Kinds: []string{"Deployment"}, APIVersions: []string{"apps/v1beta1"}},
+		},
+		{
+			name:     "only API version",
+			selector: Selector{APIVersions: []string{"apps/v1"}},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if matches := tc.selector.Matches(obj); matches != tc.expected {
+				t.Fatalf("Expected %v, but got %v.", tc.expected, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Comparator defines how strings are compared when sorting.
+type Comparator string
+
+const (
+	// LexicalComparator compares strings byte by byte (the default).
+	LexicalComparator Comparator = "lexical"
+	// NumericComparator treats strings that contain numbers as numbers.
+	NumericComparator Comparator = "numeric"
+	// NaturalComparator compares runs of digits numerically ("a2" < "a10").
+	NaturalComparator Comparator = "natural"
+	// SemverComparator compares (possibly "v"-prefixed) versions, with
+	// pre-releases sorted before their release.
+	SemverComparator Comparator = "semver"
+	// IPComparator compares IP addresses and CIDRs.
+	IPComparator Comparator = "ip"
+	// QuantityComparator compares Kubernetes quantities like "500m" or "1Gi".
+	QuantityComparator Comparator = "quantity"
+)
+
+var comparators = []Comparator{
+	LexicalComparator,
+	NumericComparator,
+	NaturalComparator,
+	SemverComparator,
+	IPComparator,
+	QuantityComparator,
+}
+
+func (c Comparator) Validate() error {
+	if c == "" {
+		return nil
+	}
+
+	for _, known := range comparators {
+		if c == known {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown comparator %q, must be one of %v", c, comparators)
+}
+
+// typeRank defines the order between values of different types:
+// null < bool < number < string < object/list.
+func typeRank(value any) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int, int32, int64, float32, float64:
+		return 2
+	case string:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// compareValues is a total order over all values that can appear in
+// unstructured objects. Strings are compared using the given comparator.
+func compareValues(a, b any, comparator Comparator) int {
+	// numeric comparators also handle numbers given as strings
+	if comparator == NumericComparator || comparator == QuantityComparator {
+		a = parseNumber(a, comparator)
+		b = parseNumber(b, comparator)
+	}
+
+	aRank := typeRank(a)
+	bRank := typeRank(b)
+
+	if aRank != bRank {
+		return cmp.Compare(aRank, bRank)
+	}
+
+	switch aValue := a.(type) {
+	case nil:
+		return 0
+
+	case bool:
+		bValue := b.(bool)
+		if aValue == bValue {
+			return 0
+		}
+		if !aValue {
+			return -1
+		}
+		return 1
+
+	case string:
+		return compareStrings(aValue, b.(string), comparator)
+
+	default:
+		if aRank == 2 {
+			aNumber, _ := jsonpath.ToFloat(a)
+			bNumber, _ := jsonpath.ToFloat(b)
+
+			return cmp.Compare(aNumber, bNumber)
+		}
+
+		// objects and lists have no natural order, but their JSON encoding
+		// (with sorted keys) is at least deterministic
+		return strings.Compare(encodeJSON(a), encodeJSON(b))
+	}
+}
+
+func parseNumber(value any, comparator Comparator) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	if comparator == QuantityComparator {
+		if q, err := resource.ParseQuantity(s); err == nil {
+			return q.AsApproximateFloat64()
+		}
+
+		return value
+	}
+
+	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return f
+	}
+
+	return value
+}
+
+// parseVersion parses semantic versions including their pre-release tags
+// and falls back to generic versions like "1.2" that are not valid semver.
+func parseVersion(value string) (*version.Version, error) {
+	if v, err := version.ParseSemantic(value); err == nil {
+		return v, nil
+	}
+
+	return version.ParseGeneric(value)
+}
+
+func encodeJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
+
+func compareStrings(a, b string, comparator Comparator) int {
+	var diff int
+
+	switch comparator {
+	case NaturalComparator:
+		diff = compareNatural(a, b)
+	case SemverComparator:
+		diff = compareParsed(a, b, parseVersion, func(x, y *version.Version) int {
+			switch {
+			case x.LessThan(y):
+				return -1
+			case y.LessThan(x):
+				return 1
+			default:
+				return 0
+			}
+		})
+	case IPComparator:
+		diff = compareParsed(a, b, parseIP, func(x, y netip.Prefix) int {
+			if diff := x.Addr().Compare(y.Addr()); diff != 0 {
+				return diff
+			}
+
+			return cmp.Compare(x.Bits(), y.Bits())
+		})
+	}
+
+	// make the order total, even if the comparator considers both equal
+	// (like "1.0" and "v1.0"); unparseable values are also sorted this way
+	if diff == 0 {
+		diff = strings.Compare(a, b)
+	}
+
+	return diff
+}
+
+// compareParsed sorts values that could be parsed before all other values.
+func compareParsed[T any](a, b string, parse func(string) (T, error), compare func(T, T) int) int {
+	aParsed, aErr := parse(a)
+	bParsed, bErr := parse(b)
+
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return 1
+	case bErr != nil:
+		return -1
+	default:
+		return compare(aParsed, bParsed)
+	}
+}
+
+func parseIP(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// compareNatural compares runs of digits by their numeric value and
+// everything else byte by byte.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		aChunk := nextChunk(a)
+		bChunk := nextChunk(b)
+
+		a = a[len(aChunk):]
+		b = b[len(bChunk):]
+
+		if isDigit(aChunk[0]) && isDigit(bChunk[0]) {
+			aNumber := strings.TrimLeft(aChunk, "0")
+			bNumber := strings.TrimLeft(bChunk, "0")
+
+			// longer numbers (without leading zeros) are larger
+			if diff := cmp.Compare(len(aNumber), len(bNumber)); diff != 0 {
+				return diff
+			}
+
+			if diff := strings.Compare(aNumber, bNumber); diff != 0 {
+				return diff
+			}
+
+			continue
+		}
+
+		if diff := strings.Compare(aChunk, bChunk); diff != 0 {
+			return diff
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+func nextChunk(s string) string {
+	digits := isDigit(s[0])
+
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+
+	return s[:i]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
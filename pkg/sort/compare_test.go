@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestSortSliceByValue(t *testing.T) {
+	testcases := []struct {
+		name       string
+		comparator Comparator
+		input      []any
+		expected   []any
+	}{
+		{
+			name:     "mixed types",
+			input:    []any{"b", map[string]any{"a": "b"}, int64(2), nil, true, "a", 1.5, false, []any{"x"}},
+			expected: []any{nil, false, true, 1.5, int64(2), "a", "b", []any{"x"}, map[string]any{"a": "b"}},
+		},
+		{
+			name:     "numbers are compared numerically",
+			input:    []any{int64(8080), int64(443), int64(80)},
+			expected: []any{int64(80), int64(443), int64(8080)},
+		},
+		{
+			name:     "lexical",
+			input:    []any{"a10", "a9", "A1"},
+			expected: []any{"A1", "a10", "a9"},
+		},
+		{
+			name:       "numeric",
+			comparator: NumericComparator,
+			input:      []any{"10", "foo", int64(9), "1.5"},
+			expected:   []any{"1.5", int64(9), "10", "foo"},
+		},
+		{
+			name:       "natural",
+			comparator: NaturalComparator,
+			input:      []any{"node10", "node9", "node09", "node", "node1b", "node1a"},
+			expected:   []any{"node", "node1a", "node1b", "node09", "node9", "node10"},
+		},
+		{
+			name:       "semver",
+			comparator: SemverComparator,
+			input:      []any{"v1.10.0", "latest", "1.9.2", "v1.9.10"},
+			expected:   []any{"1.9.2", "v1.9.10", "v1.10.0", "latest"},
+		},
+		{
+			name:       "semver pre-releases",
+			comparator: SemverComparator,
+			input:      []any{"1.0.0", "1.0.0-rc.10", "1.0.0-alpha", "1.0.0-rc.2", "0.9", "1.0.0-beta"},
+			expected:   []any{"0.9", "1.0.0-alpha", "1.0.0-beta", "1.0.0-rc.2", "1.0.0-rc.10", "1.0.0"},
+		},
+		{
+			name:       "ip",
+			comparator: IPComparator,
+			input:      []any{"::1", "10.0.0.10", "invalid", "10.0.0.0/8", "10.0.0.9"},
+			expected:   []any{"10.0.0.0/8", "10.0.0.9", "10.0.0.10", "::1", "invalid"},
+		},
+		{
+			name:       "quantity",
+			comparator: QuantityComparator,
+			input:      []any{"1Gi", "500Mi", "2", "100m"},
+			expected:   []any{"100m", "2", "500Mi", "1Gi"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := sortSlice(tc.input, SortingRule{ByValue: ptr.To(true), Comparator: tc.comparator})
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestComparatorValidate(t *testing.T) {
+	for _, c := range append(comparators, "") {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Comparator %q should be valid, but got: %v", c, err)
+		}
+	}
+
+	if err := Comparator("random").Validate(); err == nil {
+		t.Error("Unknown comparator should not be valid.")
+	}
+}
+
+func TestSortSliceWithOrdering(t *testing.T) {
+	containers := func(names ...string) []any {
+		result := []any{}
+		for _, name := range names {
+			result = append(result, map[string]any{"name": name})
+		}
+		return result
+	}
+
+	testcases := []struct {
+		name     string
+		rule     SortingRule
+		input    []any
+		expected []any
+	}{
+		{
+			name:     "descending values",
+			rule:     SortingRule{ByValue: ptr.To(true), Order: DescendingOrder},
+			input:    []any{"b", "c", "a"},
+			expected: []any{"c", "b", "a"},
+		},
+		{
+			name:     "priority values first",
+			rule:     SortingRule{ByValue: ptr.To(true), Priority: []string{"kube-system", "default"}},
+			input:    []any{"b", "default", "a", "kube-system"},
+			expected: []any{"kube-system", "default", "a", "b"},
+		},
+		{
+			name:     "priority values last",
+			rule:     SortingRule{ByValue: ptr.To(true), Priority: []string{"kube-system", "default"}, PriorityPosition: PriorityLast},
+			input:    []any{"b", "default", "a", "kube-system"},
+			expected: []any{"a", "b", "kube-system", "default"},
+		},
+		{
+			name:     "priority is not affected by descending order",
+			rule:     SortingRule{ByValue: ptr.To(true), Priority: []string{"x"}, Order: DescendingOrder},
+			input:    []any{"a", "x", "b"},
+			expected: []any{"x", "b", "a"},
+		},
+		{
+			name:     "priority by key",
+			rule:     SortingRule{ByKey: SortKeys{"name"}, Priority: []string{"main"}},
+			input:    containers("sidecar", "main", "init"),
+			expected: containers("main", "init", "sidecar"),
+		},
+		{
+			name:     "numeric priority",
+			rule:     SortingRule{ByValue: ptr.To(true), Priority: []string{"443"}},
+			input:    []any{int64(80), int64(443), int64(8080)},
+			expected: []any{int64(443), int64(80), int64(8080)},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := sortSlice(tc.input, tc.rule)
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected %v, but got %v", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+	"go.xrstf.de/kubesort/pkg/selector"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+// RulesFromCRDs looks for CustomResourceDefinitions in the given objects and
+// turns every list in their OpenAPI schemas that is marked as a map or set
+// (using x-kubernetes-list-type) into a SortingRule for the custom resource.
+// Sets are only sorted, duplicates are kept.
+// Malformed CRDs are skipped with a warning.
+func RulesFromCRDs(objects []*unstructured.Unstructured) []SortingRule {
+	rules := []SortingRule{}
+
+	for _, obj := range objects {
+		if !isCRD(obj) {
+			continue
+		}
+
+		crdRules, err := rulesFromCRD(obj)
+		if err != nil {
+			log.Printf("Warning: ignoring invalid CustomResourceDefinition %s: %v", obj.GetName(), err)
+			continue
+		}
+
+		rules = append(rules, crdRules...)
+	}
+
+	return rules
+}
+
+func rulesFromCRD(crd *unstructured.Unstructured) ([]SortingRule, error) {
+	group, _, err := unstructured.NestedString(crd.Object, "spec", "group")
+	if err != nil {
+		return nil, err
+	}
+
+	kind, _, err := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	if err != nil {
+		return nil, err
+	}
+
+	if group == "" || kind == "" {
+		return nil, nil
+	}
+
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []SortingRule{}
+
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(version, "name")
+		if name == "" {
+			continue
+		}
+
+		schema, _, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		if schema == nil {
+			// apiextensions.k8s.io/v1beta1 allowed to define a single schema for all versions
+			schema, _, _ = unstructured.NestedMap(crd.Object, "spec", "validation", "openAPIV3Schema")
+		}
+
+		if schema == nil {
+			continue
+		}
+
+		template := SortingRule{
+			Selector: selector.Selector{
+				Kinds:       []string{kind},
+				APIVersions: []string{fmt.Sprintf("%s/%s", group, name)},
+			},
+		}
+
+		rules = append(rules, rulesFromSchema(schema, nil, template)...)
+	}
+
+	return rules, nil
+}
+
+func rulesFromSchema(schema map[string]any, path jsonpath.Path, template SortingRule) []SortingRule {
+	rules := []SortingRule{}
+
+	if items, ok := schema["items"].(map[string]any); ok && len(path) > 0 {
+		if rule := ruleFromListSchema(schema, path, template); rule != nil {
+			rules = append(rules, *rule)
+		}
+
+		rules = append(rules, rulesFromSchema(items, appendStep(path, jsonpath.WildcardStep{}), template)...)
+	}
+
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		for _, name := range names {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			rules = append(rules, rulesFromSchema(property, appendStep(path, jsonpath.KeyStep(name)), template)...)
+		}
+	}
+
+	if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+		rules = append(rules, rulesFromSchema(additional, appendStep(path, jsonpath.WildcardStep{}), template)...)
+	}
+
+	return rules
+}
+
+func appendStep(path jsonpath.Path, step jsonpath.Step) jsonpath.Path {
+	return append(slices.Clone(path), step)
+}
+
+func ruleFromListSchema(schema map[string]any, path jsonpath.Path, template SortingRule) *SortingRule {
+	listType, _ := schema["x-kubernetes-list-type"].(string)
+
+	rule := template
+	rule.Path = path.String()
+
+	switch listType {
+	case "map":
+		keys, _ := schema["x-kubernetes-list-map-keys"].([]any)
+		if len(keys) == 0 {
+			return nil
+		}
+
+		for _, k := range keys {
+			key, ok := k.(string)
+			if !ok || key == "" {
+				return nil
+			}
+
+			// map keys are always top-level fields, which might need quoting
+			rule.ByKey = append(rule.ByKey, jsonpath.Path{jsonpath.KeyStep(key)}.String())
+		}
+
+	case "set":
+		// removing duplicates is left to the user (see SortingRule.Unique)
+		rule.ByValue = ptr.To(true)
+
+	default:
+		return nil
+	}
+
+	if err := rule.Compile(); err != nil {
+		return nil
+	}
+
+	return &rule
+}
@@ -0,0 +1,255 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRulesFromCRDs(t *testing.T) {
+	testcases := []struct {
+		name     string
+		crds     []string
+		expected []string
+	}{
+		{
+			name: "list types",
+			crds: []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                ports:
+                  type: array
+                  x-kubernetes-list-type: map
+                  x-kubernetes-list-map-keys: [port, protocol]
+                  items:
+                    type: object
+                tags:
+                  type: array
+                  x-kubernetes-list-type: set
+                  items:
+                    type: string
+                steps:
+                  type: array
+                  x-kubernetes-list-type: atomic
+                  items:
+                    type: string
+                args:
+                  type: array
+                  items:
+                    type: string
+`},
+			expected: []string{
+				"example.com/v1 Widget spec.ports byKey=[port protocol]",
+				"example.com/v1 Widget spec.tags byValue",
+			},
+		},
+		{
+			name: "nested lists",
+			crds: []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            spec:
+              type: object
+              properties:
+                groups:
+                  type: array
+                  x-kubernetes-list-type: map
+                  x-kubernetes-list-map-keys: [app.kubernetes.io/name]
+                  items:
+                    type: object
+                    properties:
+                      members:
+                        type: array
+                        x-kubernetes-list-type: set
+                        items:
+                          type: string
+                labels:
+                  type: object
+                  additionalProperties:
+                    type: array
+                    x-kubernetes-list-type: set
+                    items:
+                      type: string
+`},
+			expected: []string{
+				`example.com/v1 Widget spec.groups byKey=[["app.kubernetes.io/name"]]`,
+				"example.com/v1 Widget spec.groups[*].members byValue",
+				"example.com/v1 Widget spec.labels[*] byValue",
+			},
+		},
+		{
+			name: "multiple versions",
+			crds: []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1alpha1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            tags:
+              type: array
+              x-kubernetes-list-type: set
+              items:
+                type: string
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            labels:
+              type: array
+              x-kubernetes-list-type: set
+              items:
+                type: string
+`},
+			expected: []string{
+				"example.com/v1alpha1 Widget tags byValue",
+				"example.com/v1 Widget labels byValue",
+			},
+		},
+		{
+			name: "malformed CRDs are skipped",
+			crds: []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: broken.example.com
+spec:
+  group: example.com
+  names:
+    kind: Broken
+  versions: v1
+`, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: invalid.example.com
+spec:
+  group: example.com
+  names:
+    kind: Invalid
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            noKeys:
+              type: array
+              x-kubernetes-list-type: map
+              items: {}
+            badKeys:
+              type: array
+              x-kubernetes-list-type: map
+              x-kubernetes-list-map-keys: [[name]]
+              items: {}
+            notASchema: true
+    - schema: {}
+    - name: v2
+`, `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+          properties:
+            tags:
+              type: array
+              x-kubernetes-list-type: set
+              items:
+                type: string
+`},
+			expected: []string{
+				"example.com/v1 Widget tags byValue",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := []*unstructured.Unstructured{}
+			for _, crd := range tc.crds {
+				obj := &unstructured.Unstructured{}
+				if err := yaml.Unmarshal([]byte(crd), &obj.Object); err != nil {
+					t.Fatalf("Failed to decode CRD: %v", err)
+				}
+
+				objects = append(objects, obj)
+			}
+
+			result := []string{}
+			for _, rule := range RulesFromCRDs(objects) {
+				result = append(result, describeRule(rule))
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Unexpected rules:\n%s", cmp.Diff(tc.expected, result))
+			}
+		})
+	}
+}
+
+func describeRule(rule SortingRule) string {
+	desc := fmt.Sprintf("%s %s %s", strings.Join(rule.APIVersions, ","), strings.Join(rule.Kinds, ","), rule.Path)
+
+	if len(rule.ByKey) > 0 {
+		desc += fmt.Sprintf(" byKey=%v", []string(rule.ByKey))
+	}
+
+	if rule.ByValue != nil && *rule.ByValue {
+		desc += " byValue"
+	}
+
+	return desc
+}
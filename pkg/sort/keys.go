@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+)
+
+// SortKeys is an ordered list of paths (relative to each list item) to
+// sort by; later keys are only compared if all previous keys are equal.
+// In YAML it can be given as a single string or a list of strings.
+type SortKeys []string
+
+func (k *SortKeys) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		var key string
+		if err := value.Decode(&key); err != nil {
+			return err
+		}
+
+		*k = SortKeys{key}
+
+	case yaml.SequenceNode:
+		var keys []string
+		if err := value.Decode(&keys); err != nil {
+			return err
+		}
+
+		*k = keys
+
+	default:
+		return fmt.Errorf("line %d: byKey must be a string or a list of strings", value.Line)
+	}
+
+	return nil
+}
+
+func (k SortKeys) JSONPaths() ([]jsonpath.Path, error) {
+	paths := make([]jsonpath.Path, 0, len(k))
+
+	for _, key := range k {
+		if key == "" {
+			return nil, errors.New("key must not be empty")
+		}
+
+		path, err := jsonpath.Parse(key)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSortKeysUnmarshalYAML(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected SortKeys
+		invalid  bool
+	}{
+		{
+			input:    `byKey: name`,
+			expected: SortKeys{"name"},
+		},
+		{
+			input:    `byKey: [port, protocol]`,
+			expected: SortKeys{"port", "protocol"},
+		},
+		{
+			input:    "byKey:\n  - metadata.namespace\n  - metadata.name",
+			expected: SortKeys{"metadata.namespace", "metadata.name"},
+		},
+		{
+			input:   `byKey: {name: x}`,
+			invalid: true,
+		},
+		{
+			input:   `byKey: [[a]]`,
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			var rule struct {
+				ByKey SortKeys `yaml:"byKey"`
+			}
+
+			err := yaml.Unmarshal([]byte(tc.input), &rule)
+			if tc.invalid {
+				if err == nil {
+					t.Fatalf("Expected an error, but got %v.", rule.ByKey)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, rule.ByKey) {
+				t.Fatalf("Expected %v, but got %v.", tc.expected, rule.ByKey)
+			}
+		})
+	}
+}
+
+func TestSortKeysJSONPaths(t *testing.T) {
+	paths, err := SortKeys{"name", "metadata.name", `labels["app.kubernetes.io/name"]`}.JSONPaths()
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+
+	expected := []string{"name", "metadata.name", `labels["app.kubernetes.io/name"]`}
+	for i, path := range paths {
+		if path.String() != expected[i] {
+			t.Errorf("Expected key %d to be %q, but got %q.", i, expected[i], path.String())
+		}
+	}
+
+	for _, keys := range []SortKeys{{""}, {"name", "a[1"}} {
+		if _, err := keys.JSONPaths(); err == nil {
+			t.Errorf("Expected %v to be invalid.", keys)
+		}
+	}
+}
+
+func TestSortByKey(t *testing.T) {
+	port := func(port int64, protocol string) map[string]any {
+		item := map[string]any{"port": port}
+		if protocol != "" {
+			item["protocol"] = protocol
+		}
+
+		return item
+	}
+
+	object := func(namespace, name string) map[string]any {
+		return map[string]any{"metadata": map[string]any{"namespace": namespace, "name": name}}
+	}
+
+	testcases := []struct {
+		name     string
+		keys     SortKeys
+		input    []any
+		expected []any
+	}{
+		{
+			name:     "single key",
+			keys:     SortKeys{"port"},
+			input:    []any{port(443, "TCP"), port(80, "TCP"), port(8080, "TCP")},
+			expected: []any{port(80, "TCP"), port(443, "TCP"), port(8080, "TCP")},
+		},
+		{
+			name:     "later keys break ties",
+			keys:     SortKeys{"port", "protocol"},
+			input:    []any{port(53, "UDP"), port(80, "TCP"), port(53, "TCP")},
+			expected: []any{port(53, "TCP"), port(53, "UDP"), port(80, "TCP")},
+		},
+		{
+			name:     "nested key paths",
+			keys:     SortKeys{"metadata.namespace", "metadata.name"},
+			input:    []any{object("b", "a"), object("a", "b"), object("a", "a")},
+			expected: []any{object("a", "a"), object("a", "b"), object("b", "a")},
+		},
+		{
+			name:     "items without key come first",
+			keys:     SortKeys{"port", "protocol"},
+			input:    []any{port(53, "UDP"), port(53, ""), map[string]any{"name": "x"}, "scalar"},
+			expected: []any{map[string]any{"name": "x"}, "scalar", port(53, ""), port(53, "UDP")},
+		},
+		{
+			name:     "equal keys keep their order",
+			keys:     SortKeys{"port"},
+			input:    []any{port(80, "UDP"), port(80, "TCP"), port(80, "SCTP")},
+			expected: []any{port(80, "UDP"), port(80, "TCP"), port(80, "SCTP")},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, err := sortSlice(tc.input, SortingRule{ByKey: tc.keys})
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, sorted) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(tc.expected, sorted))
+			}
+		})
+	}
+}
+
+func TestCompareByKey(t *testing.T) {
+	keys, err := SortKeys{"a", "b.c"}.JSONPaths()
+	if err != nil {
+		t.Fatalf("Failed to parse keys: %v", err)
+	}
+
+	compare := compareByKey(keys, "")
+
+	testcases := []struct {
+		a        any
+		b        any
+		expected int
+	}{
+		{a: map[string]any{"a": "x"}, b: map[string]any{"a": "y"}, expected: -1},
+		{a: map[string]any{"a": "y"}, b: map[string]any{"a": "x"}, expected: 1},
+		{a: map[string]any{"a": "x", "b": map[string]any{"c": int64(2)}}, b: map[string]any{"a": "x", "b": map[string]any{"c": int64(10)}}, expected: -1},
+		{a: map[string]any{"a": "x", "b": map[string]any{"c": int64(1)}}, b: map[string]any{"a": "x", "b": map[string]any{"c": int64(1)}}, expected: 0},
+		{a: map[string]any{"b": map[string]any{"c": int64(9)}}, b: map[string]any{"a": "x"}, expected: -1},
+		{a: map[string]any{"a": "x", "b": map[string]any{}}, b: map[string]any{"a": "x", "b": map[string]any{"c": int64(1)}}, expected: -1},
+		{a: "scalar", b: map[string]any{"a": "x"}, expected: -1},
+		{a: "scalar", b: []any{}, expected: 0},
+	}
+
+	for _, tc := range testcases {
+		if result := compare(tc.a, tc.b); sign(result) != tc.expected {
+			t.Errorf("compare(%v, %v): expected %d, but got %d", tc.a, tc.b, tc.expected, result)
+		}
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	default:
+		return 0
+	}
+}
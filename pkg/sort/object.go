@@ -8,87 +8,162 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"slices"
 	"strings"
 
 	"go.xrstf.de/kubesort/pkg/jsonpath"
+	"go.xrstf.de/kubesort/pkg/selector"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type SortingRule struct {
-	Kinds        []string `yaml:"kinds,omitempty"`
+	selector.Selector `yaml:",inline"`
+
 	Path         string   `yaml:"path"`
-	ByKey        string   `yaml:"byKey,omitempty"`
+	ByKey        SortKeys `yaml:"byKey,omitempty"`
 	ByValue      *bool    `yaml:"byValue,omitempty"`
 	RBACRules    *bool    `yaml:"rbacRules,omitempty"`
 	RBACSubjects *bool    `yaml:"rbacSubjects,omitempty"`
+	// Comparator defines how byKey and byValue compare strings.
+	Comparator Comparator `yaml:"comparator,omitempty"`
+	// Order can be set to "desc" to reverse byKey and byValue sorting.
+	Order SortOrder `yaml:"order,omitempty"`
+	// Priority lists values (of the first key for byKey rules) that are
+	// pinned to the front of the list in the given order, or to the back if
+	// PriorityPosition is "last".
+	Priority         []string         `yaml:"priority,omitempty"`
+	PriorityPosition PriorityPosition `yaml:"priorityPosition,omitempty"`
+	// Unique removes duplicates after sorting: exact duplicates for byValue,
+	// items with equal keys for byKey (keeping the last one of them).
+	Unique *bool `yaml:"unique,omitempty"`
+	// Set sorts by value and removes duplicates, like byValue with unique.
+	Set *bool `yaml:"set,omitempty"`
+	// PodSpec sorts containers, volumes etc. in every PodSpec at Path, or
+	// in every PodSpec anywhere in the object if no path is given.
+	PodSpec *bool `yaml:"podSpec,omitempty"`
+
+	// path and keys are set by Compile.
+	path jsonpath.Path
+	keys []jsonpath.Path
 }
 
 func (r SortingRule) Validate() error {
 	var methods []string
-	if r.ByKey != "" {
+	if len(r.ByKey) > 0 {
 		methods = append(methods, "byKey")
 	}
 	if r.ByValue != nil {
 		methods = append(methods, "byValue")
 	}
+	if r.Set != nil {
+		methods = append(methods, "set")
+	}
 	if r.RBACRules != nil {
 		methods = append(methods, "rbacRules")
 	}
 	if r.RBACSubjects != nil {
 		methods = append(methods, "rbacSubjects")
 	}
+	if r.PodSpec != nil {
+		methods = append(methods, "podSpec")
+	}
 
 	switch len(methods) {
 	case 0:
 		return errors.New("no sorting method specified")
 	case 1:
-		return nil
+		// ok
 	default:
 		return fmt.Errorf("cannot specify multiple sorting methods: %v", methods)
 	}
-}
 
-func (r SortingRule) JSONPath() jsonpath.Path {
-	path := jsonpath.Path{}
+	if err := r.Comparator.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.Order.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.PriorityPosition.Validate(); err != nil {
+		return err
+	}
+
+	if len(r.ByKey) == 0 && r.ByValue == nil && r.Set == nil {
+		if r.Comparator != "" || r.Order != "" || len(r.Priority) > 0 || r.PriorityPosition != "" || r.Unique != nil {
+			return errors.New("comparator, order, priority and unique can only be used with byKey, byValue or set")
+		}
+	}
+
+	if _, err := r.ByKey.JSONPaths(); err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
 
-	parts := strings.Split(r.Path, ".")
-	for _, part := range parts {
-		if strings.HasSuffix(part, "[]") {
-			part = strings.TrimSuffix(part, "[]")
-			path = append(path, jsonpath.KeyStep(part), wildcardStep{})
-		} else {
-			path = append(path, jsonpath.KeyStep(part))
+	// only PodSpecs can be discovered automatically
+	if r.Path == "" {
+		if r.PodSpec == nil {
+			return errors.New("no path specified")
 		}
+
+		return nil
 	}
 
-	return path
+	_, err := r.JSONPath()
+
+	return err
 }
 
-func (r SortingRule) Matches(obj *unstructured.Unstructured) bool {
-	if len(r.Kinds) == 0 {
-		return true
+// Compile parses the path and keys once, so they do not have to be parsed
+// again whenever the rule is applied to an object.
+func (r *SortingRule) Compile() error {
+	path, err := jsonpath.Parse(r.Path)
+	if err != nil {
+		return err
 	}
 
-	return slices.Contains(r.Kinds, obj.GetKind())
+	keys, err := r.ByKey.JSONPaths()
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+
+	r.path = path
+	r.keys = keys
+
+	return nil
+}
+
+func (r SortingRule) JSONPath() (jsonpath.Path, error) {
+	if r.path != nil {
+		return r.path, nil
+	}
+
+	return jsonpath.Parse(r.Path)
 }
 
-type wildcardStep struct{}
+func (r SortingRule) sortKeys() ([]jsonpath.Path, error) {
+	if r.keys != nil {
+		return r.keys, nil
+	}
 
-func (wildcardStep) Keep(key any, value any) (bool, error) {
-	return true, nil
+	return r.ByKey.JSONPaths()
 }
 
 func Object(obj *unstructured.Unstructured, rules []SortingRule) (*unstructured.Unstructured, error) {
 	data := obj.Object
 
+	warn := func(format string, args ...any) {
+		log.Printf("Warning: %s: %s", describeObject(obj), fmt.Sprintf(format, args...))
+	}
+
 	for _, rule := range rules {
 		if !rule.Matches(obj) {
 			continue
 		}
 
-		patched, err := applyRule(data, rule)
+		patched, err := applyRule(data, rule, warn)
 		if err != nil {
 			return nil, err
 		}
@@ -101,8 +176,21 @@ func Object(obj *unstructured.Unstructured, rules []SortingRule) (*unstructured.
 	return obj, nil
 }
 
-func applyRule(obj map[string]any, rule SortingRule) (map[string]any, error) {
-	patched, err := jsonpath.Patch(obj, rule.JSONPath(), func(exists bool, key, val any) (any, error) {
+func applyRule(obj map[string]any, rule SortingRule, warn warnFunc) (map[string]any, error) {
+	if rule.PodSpec != nil {
+		if !*rule.PodSpec {
+			return obj, nil
+		}
+
+		return applyPodSpecRule(obj, rule, warn)
+	}
+
+	path, err := rule.JSONPath()
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := jsonpath.Patch(obj, path, func(exists bool, key, val any) (any, error) {
 		if !exists {
 			return nil, nil
 		}
@@ -112,7 +200,25 @@ func applyRule(obj map[string]any, rule SortingRule) (map[string]any, error) {
 			return val, nil
 		}
 
-		return sortSlice(list, rule)
+		sorted, err := sortSlice(list, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		if !rule.removesDuplicates() {
+			return sorted, nil
+		}
+
+		unique, dropped, err := removeDuplicates(sorted, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dropped) > 0 {
+			warn("removed %d duplicate item(s) from %s: %s", len(dropped), rule.Path, describeItems(dropped))
+		}
+
+		return unique, nil
 	})
 	if err != nil {
 		return nil, err
@@ -122,12 +228,31 @@ func applyRule(obj map[string]any, rule SortingRule) (map[string]any, error) {
 }
 
 func sortSlice(items []any, rule SortingRule) ([]any, error) {
-	if rule.ByKey != "" {
-		return sortSliceByKey(items, rule.ByKey), nil
+	if len(rule.ByKey) > 0 {
+		keys, err := rule.sortKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		priorityValue := func(item any) (any, bool) {
+			return getField(item, keys[0])
+		}
+
+		compare := withOrdering(compareByKey(keys, rule.Comparator), rule, priorityValue)
+		slices.SortStableFunc(items, compare)
+
+		return items, nil
 	}
 
-	if rule.ByValue != nil && *rule.ByValue {
-		return sortSliceByValue(items), nil
+	if rule.sortsByValue() {
+		priorityValue := func(item any) (any, bool) {
+			return item, true
+		}
+
+		compare := withOrdering(compareByValue(rule.Comparator), rule, priorityValue)
+		slices.SortStableFunc(items, compare)
+
+		return items, nil
 	}
 
 	if rule.RBACRules != nil && *rule.RBACRules {
@@ -141,59 +266,47 @@ func sortSlice(items []any, rule SortingRule) ([]any, error) {
 	return nil, errors.New("no supporting sorting mechanism configured")
 }
 
-func sortSliceByValue(items []any) []any {
-	slices.SortFunc(items, func(a, b any) int {
-		aValue, ok := a.(string)
-		if !ok {
-			return -1
-		}
-
-		bValue, ok := b.(string)
-		if !ok {
-			return 1
-		}
-
-		return strings.Compare(aValue, bValue)
-	})
-
-	return items
+func compareByValue(comparator Comparator) compareFunc {
+	return func(a, b any) int {
+		return compareValues(a, b, comparator)
+	}
 }
 
-func sortSliceByKey(items []any, keyField string) []any {
-	slices.SortFunc(items, func(a, b any) int {
-		aKey, ok := getField(a, keyField)
-		if !ok {
-			return -1
-		}
+func compareByKey(keys []jsonpath.Path, comparator Comparator) compareFunc {
+	return func(a, b any) int {
+		for _, key := range keys {
+			aKey, aOK := getField(a, key)
+			bKey, bOK := getField(b, key)
 
-		bKey, ok := getField(b, keyField)
-		if !ok {
-			return 1
-		}
-
-		return strings.Compare(aKey, bKey)
-	})
+			switch {
+			case !aOK && !bOK:
+				continue
+			case !aOK:
+				return -1
+			case !bOK:
+				return 1
+			}
 
-	return items
-}
+			if diff := compareValues(aKey, bKey, comparator); diff != 0 {
+				return diff
+			}
+		}
 
-func getField(val any, fieldName string) (string, bool) {
-	asMap, ok := val.(map[string]any)
-	if !ok {
-		return "", false
+		return 0
 	}
+}
 
-	value, ok := asMap[fieldName]
-	if !ok {
-		return "", false
+func getField(val any, key jsonpath.Path) (any, bool) {
+	if _, ok := val.(map[string]any); !ok {
+		return nil, false
 	}
 
-	asString, ok := value.(string)
-	if !ok {
-		return "", false
+	value, err := jsonpath.Get(val, key)
+	if err != nil {
+		return nil, false
 	}
 
-	return asString, true
+	return value, true
 }
 
 func sortRBACRules(rules []any) []any {
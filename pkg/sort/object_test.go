@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+)
+
+func TestSortingRuleCompile(t *testing.T) {
+	rule := SortingRule{Path: "spec.ports", ByKey: SortKeys{"port", "protocol"}}
+	if err := rule.Compile(); err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+
+	// compiled rules must not parse their path and keys again
+	rule.Path = "[invalid"
+	rule.ByKey = SortKeys{"[invalid"}
+
+	path, err := rule.JSONPath()
+	if err != nil {
+		t.Fatalf("Failed to get path: %v", err)
+	}
+
+	if path.String() != "spec.ports" {
+		t.Errorf("Expected path spec.ports, but got %q.", path.String())
+	}
+
+	keys, err := rule.sortKeys()
+	if err != nil {
+		t.Fatalf("Failed to get keys: %v", err)
+	}
+
+	if len(keys) != 2 || keys[0].String() != "port" || keys[1].String() != "protocol" {
+		t.Errorf("Expected keys [port protocol], but got %v.", keys)
+	}
+
+	for _, invalid := range []SortingRule{
+		{Path: "[invalid"},
+		{Path: "spec.ports", ByKey: SortKeys{""}},
+	} {
+		if err := invalid.Compile(); err == nil {
+			t.Errorf("Expected rule with path %q and keys %v to be invalid.", invalid.Path, invalid.ByKey)
+		}
+	}
+}
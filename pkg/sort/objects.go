@@ -4,8 +4,8 @@
 package sort
 
 import (
+	"cmp"
 	"fmt"
-	"log"
 	"slices"
 	"strings"
 
@@ -13,39 +13,49 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-func Objects(objects []*unstructured.Unstructured, objectRules []SortingRule) ([]*unstructured.Unstructured, error) {
+// Options configures how objects are sorted.
+type Options struct {
+	// Rules are applied to each object to sort lists within it.
+	Rules []SortingRule
+	// Order decides the order of the objects themselves.
+	Order ObjectOrder
+	// Scopes determines which objects are cluster-scoped. If nil, every
+	// object without a namespace is considered cluster-scoped.
+	Scopes ScopeResolver
+}
+
+type ScopeResolver interface {
+	IsClusterScoped(obj *unstructured.Unstructured) bool
+}
+
+type namespaceHeuristic struct{}
+
+func (namespaceHeuristic) IsClusterScoped(obj *unstructured.Unstructured) bool {
+	return obj.GetNamespace() == ""
+}
+
+func Objects(objects []*unstructured.Unstructured, opts Options) ([]*unstructured.Unstructured, error) {
+	rules := newRuleIndex(opts.Rules)
+
 	sortedObjects := make([]*unstructured.Unstructured, 0, len(objects))
 	for i := range objects {
-		sorted, err := Object(objects[i], objectRules)
+		sorted, err := Object(objects[i], rules.forKind(objects[i].GetKind()))
 		if err != nil {
 			return nil, fmt.Errorf("failed to sort object: %w", err)
 		}
 		sortedObjects = append(sortedObjects, sorted)
 	}
 
-	slices.SortStableFunc(sortedObjects, func(a, b *unstructured.Unstructured) int {
-		// CRDs always come first
-		aCRD := isCRD(a)
-		bCRD := isCRD(b)
-
-		if aCRD != bCRD {
-			if aCRD {
-				return -1
-			} else {
-				return 1
-			}
-		}
+	scopes := opts.Scopes
+	if scopes == nil {
+		scopes = namespaceHeuristic{}
+	}
 
-		// cluster-scoped resources are next (this includes Namespaces themselves)
-		aClusterScoped := isClusterScoped(a)
-		bClusterScoped := isClusterScoped(b)
+	rank := opts.Order.rankFunc(scopes)
 
-		if aClusterScoped != bClusterScoped {
-			if aClusterScoped {
-				return -1
-			} else {
-				return 1
-			}
+	slices.SortStableFunc(sortedObjects, func(a, b *unstructured.Unstructured) int {
+		if diff := cmp.Compare(rank(a), rank(b)); diff != 0 {
+			return diff
 		}
 
 		// next we compare GVK (split APIVersion to make sure core API groups get sorted before others (because it's ""))
@@ -81,18 +91,57 @@ func Objects(objects []*unstructured.Unstructured, objectRules []SortingRule) ([
 			return strings.Compare(a.GetName(), b.GetName())
 		}
 
-		log.Print("Found two objects that are the same?")
-
+		// duplicates are handled before sorting (see pkg/duplicates)
 		return 0
 	})
 
 	return sortedObjects, nil
 }
 
-func isCRD(obj *unstructured.Unstructured) bool {
-	return obj.GetKind() == "CustomResourceDefinition"
+// ruleIndex groups rules by kind, so that each object only has to be checked
+// against the few rules that can apply to it instead of all of them.
+type ruleIndex struct {
+	byKind map[string][]SortingRule
+	// generic are the rules without kinds, which apply to every kind.
+	generic []SortingRule
 }
 
-func isClusterScoped(obj *unstructured.Unstructured) bool {
-	return obj.GetNamespace() == ""
+func newRuleIndex(rules []SortingRule) ruleIndex {
+	index := ruleIndex{
+		byKind: map[string][]SortingRule{},
+	}
+
+	for _, rule := range rules {
+		if len(rule.Kinds) == 0 {
+			index.generic = append(index.generic, rule)
+		}
+
+		for _, kind := range rule.Kinds {
+			index.byKind[kind] = nil
+		}
+	}
+
+	// rules must be applied in their original order, so generic rules are
+	// interleaved with the kind-specific ones
+	for kind := range index.byKind {
+		for _, rule := range rules {
+			if len(rule.Kinds) == 0 || slices.Contains(rule.Kinds, kind) {
+				index.byKind[kind] = append(index.byKind[kind], rule)
+			}
+		}
+	}
+
+	return index
+}
+
+func (i ruleIndex) forKind(kind string) []SortingRule {
+	if rules, ok := i.byKind[kind]; ok {
+		return rules
+	}
+
+	return i.generic
+}
+
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "CustomResourceDefinition"
 }
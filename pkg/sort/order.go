@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OrderStrategy decides in which order objects are printed.
+type OrderStrategy string
+
+const (
+	// DefaultOrder puts CRDs first, then cluster-scoped objects, and sorts
+	// everything else by group, version, kind, namespace and name. This is
+	// meant to produce stable, diff-friendly output.
+	DefaultOrder OrderStrategy = "default"
+	// HelmOrder uses the same kind order that Helm uses when installing charts.
+	HelmOrder OrderStrategy = "helm"
+	// KubectlOrder puts objects into an order in which they can be safely
+	// created using `kubectl apply`.
+	KubectlOrder OrderStrategy = "kubectl"
+	// CustomOrder uses the kinds configured in ObjectOrder.Kinds.
+	CustomOrder OrderStrategy = "custom"
+)
+
+var orderStrategies = []OrderStrategy{
+	DefaultOrder,
+	HelmOrder,
+	KubectlOrder,
+	CustomOrder,
+}
+
+// helmInstallOrder is taken from Helm's releaseutil.InstallOrder; unknown
+// kinds are installed last.
+var helmInstallOrder = []string{
+	"PriorityClass",
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"SecretList",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleList",
+	"ClusterRoleBinding",
+	"ClusterRoleBindingList",
+	"Role",
+	"RoleList",
+	"RoleBinding",
+	"RoleBindingList",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+// kubectlApplyOrder creates everything other objects might depend on first.
+// Custom resources (and all other unknown kinds) can only be created after
+// their CRDs, and webhooks come last so they cannot block the creation of
+// objects before their backing services are running.
+var kubectlApplyOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"StorageClass",
+	"IngressClass",
+	"RuntimeClass",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"PersistentVolume",
+	"ResourceQuota",
+	"LimitRange",
+	"NetworkPolicy",
+	"ServiceAccount",
+	"Role",
+	"RoleBinding",
+	"Secret",
+	"ConfigMap",
+	"PersistentVolumeClaim",
+	"Service",
+	"Endpoints",
+	"EndpointSlice",
+	"PodDisruptionBudget",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"DaemonSet",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"HorizontalPodAutoscaler",
+	"Ingress",
+	"*",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+}
+
+// ObjectOrder configures the order of objects.
+type ObjectOrder struct {
+	Strategy OrderStrategy `yaml:"strategy,omitempty"`
+	// Kinds is the list of kind patterns for the custom strategy. Patterns
+	// are either a kind ("Deployment") or a kind and group, separated by a
+	// dot ("Certificate.cert-manager.io"), and can contain wildcards
+	// ("*.cert-manager.io"). Objects are ranked by the first pattern they
+	// match; "*" marks where all other objects go (by default, at the end).
+	Kinds []string `yaml:"kinds,omitempty"`
+}
+
+func (o ObjectOrder) Validate() error {
+	switch o.Strategy {
+	case "", DefaultOrder, HelmOrder, KubectlOrder:
+		if len(o.Kinds) > 0 {
+			return fmt.Errorf("kinds can only be configured for the %q strategy", CustomOrder)
+		}
+
+	case CustomOrder:
+		if len(o.Kinds) == 0 {
+			return errors.New("no kinds configured")
+		}
+
+	default:
+		return fmt.Errorf("unknown strategy %q, must be one of %v", o.Strategy, orderStrategies)
+	}
+
+	for _, pattern := range o.Kinds {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid kind pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// rankFunc returns a function that assigns each object a rank; objects are
+// sorted by their rank first and only then by group, version, kind etc.
+func (o ObjectOrder) rankFunc(scopes ScopeResolver) func(obj *unstructured.Unstructured) int {
+	switch o.Strategy {
+	case HelmOrder:
+		return kindRanker(helmInstallOrder)
+	case KubectlOrder:
+		return kindRanker(kubectlApplyOrder)
+	case CustomOrder:
+		return kindRanker(o.Kinds)
+	default:
+		return defaultRanker(scopes)
+	}
+}
+
+func defaultRanker(scopes ScopeResolver) func(obj *unstructured.Unstructured) int {
+	return func(obj *unstructured.Unstructured) int {
+		switch {
+		// CRDs always come first
+		case isCRD(obj):
+			return 0
+		// cluster-scoped resources are next (this includes Namespaces themselves)
+		case scopes.IsClusterScoped(obj):
+			return 1
+		default:
+			return 2
+		}
+	}
+}
+
+func kindRanker(patterns []string) func(obj *unstructured.Unstructured) int {
+	return func(obj *unstructured.Unstructured) int {
+		kind := obj.GetKind()
+		qualifiedKind := kind
+		if group := obj.GroupVersionKind().Group; group != "" {
+			qualifiedKind = fmt.Sprintf("%s.%s", kind, group)
+		}
+
+		catchAll := len(patterns)
+
+		for i, pattern := range patterns {
+			if pattern == "*" {
+				catchAll = i
+				continue
+			}
+
+			subject := kind
+			if strings.Contains(pattern, ".") {
+				subject = qualifiedKind
+			}
+
+			if matched, _ := path.Match(pattern, subject); matched {
+				return i
+			}
+		}
+
+		return catchAll
+	}
+}
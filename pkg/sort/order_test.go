@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+
+	return obj
+}
+
+func TestObjectOrder(t *testing.T) {
+	objects := func() []*unstructured.Unstructured {
+		return []*unstructured.Unstructured{
+			newObject("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "webhook"),
+			newObject("cert-manager.io/v1", "Certificate", "app", "cert"),
+			newObject("apps/v1", "Deployment", "app", "b"),
+			newObject("apps/v1", "Deployment", "app", "a"),
+			newObject("v1", "Service", "app", "svc"),
+			newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "certificates.cert-manager.io"),
+			newObject("v1", "Namespace", "", "app"),
+			newObject("v1", "ConfigMap", "app", "cm"),
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		order    ObjectOrder
+		expected []string
+	}{
+		{
+			name: "default",
+			expected: []string{
+				"CustomResourceDefinition/certificates.cert-manager.io",
+				"Namespace/app",
+				"ValidatingWebhookConfiguration/webhook",
+				"ConfigMap/cm",
+				"Service/svc",
+				"Deployment/a",
+				"Deployment/b",
+				"Certificate/cert",
+			},
+		},
+		{
+			name:  "helm",
+			order: ObjectOrder{Strategy: HelmOrder},
+			expected: []string{
+				"Namespace/app",
+				"ConfigMap/cm",
+				"CustomResourceDefinition/certificates.cert-manager.io",
+				"Service/svc",
+				"Deployment/a",
+				"Deployment/b",
+				"ValidatingWebhookConfiguration/webhook",
+				"Certificate/cert",
+			},
+		},
+		{
+			name:  "kubectl",
+			order: ObjectOrder{Strategy: KubectlOrder},
+			expected: []string{
+				"Namespace/app",
+				"CustomResourceDefinition/certificates.cert-manager.io",
+				"ConfigMap/cm",
+				"Service/svc",
+				"Deployment/a",
+				"Deployment/b",
+				"Certificate/cert",
+				"ValidatingWebhookConfiguration/webhook",
+			},
+		},
+		{
+			name:  "custom",
+			order: ObjectOrder{Strategy: CustomOrder, Kinds: []string{"*.cert-manager.io", "*", "Deployment", "Namespace.k8s.io"}},
+			expected: []string{
+				"Certificate/cert",
+				"ConfigMap/cm",
+				"Namespace/app",
+				"Service/svc",
+				"ValidatingWebhookConfiguration/webhook",
+				"CustomResourceDefinition/certificates.cert-manager.io",
+				"Deployment/a",
+				"Deployment/b",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.order.Validate(); err != nil {
+				t.Fatalf("Invalid order: %v", err)
+			}
+
+			sorted, err := Objects(objects(), Options{Order: tc.order})
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			result := []string{}
+			for _, obj := range sorted {
+				result = append(result, obj.GetKind()+"/"+obj.GetName())
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Fatalf("Expected\n%v\nbut got\n%v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestObjectOrderValidate(t *testing.T) {
+	invalid := []ObjectOrder{
+		{Strategy: "random"},
+		{Strategy: CustomOrder},
+		{Strategy: HelmOrder, Kinds: []string{"Deployment"}},
+		{Strategy: CustomOrder, Kinds: []string{"[Deployment"}},
+	}
+
+	for _, order := range invalid {
+		if err := order.Validate(); err == nil {
+			t.Errorf("Order %+v should not be valid.", order)
+		}
+	}
+}
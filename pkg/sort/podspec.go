@@ -0,0 +1,177 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	containerLists = []string{"containers", "initContainers", "ephemeralContainers"}
+
+	// containerFields are the JSON field names of a Container.
+	containerFields = func() map[string]struct{} {
+		fields := map[string]struct{}{}
+
+		t := reflect.TypeOf(corev1.Container{})
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			fields[name] = struct{}{}
+		}
+
+		return fields
+	}()
+
+	// podSpecRules are applied relative to each PodSpec found in an object.
+	podSpecRules = func() []SortingRule {
+		rules := []SortingRule{
+			// initContainers are executed in order and must not be sorted
+			{Path: "containers", ByKey: SortKeys{"name"}},
+			{Path: "ephemeralContainers", ByKey: SortKeys{"name"}},
+			{Path: "volumes", ByKey: SortKeys{"name"}},
+			{Path: "imagePullSecrets", ByKey: SortKeys{"name"}},
+			{Path: "hostAliases", ByKey: SortKeys{"ip"}},
+			{Path: "topologySpreadConstraints", ByKey: SortKeys{"topologyKey", "whenUnsatisfiable"}},
+			{Path: "resourceClaims", ByKey: SortKeys{"name"}},
+			{Path: "schedulingGates", ByKey: SortKeys{"name"}},
+		}
+
+		for _, list := range containerLists {
+			rules = append(rules,
+				SortingRule{Path: list + "[].env", ByKey: SortKeys{"name"}},
+				SortingRule{Path: list + "[].ports", ByKey: SortKeys{"containerPort", "protocol"}},
+				SortingRule{Path: list + "[].volumeMounts", ByKey: SortKeys{"mountPath"}},
+				SortingRule{Path: list + "[].volumeDevices", ByKey: SortKeys{"devicePath"}},
+			)
+		}
+
+		for i := range rules {
+			if err := rules[i].Compile(); err != nil {
+				panic(err)
+			}
+		}
+
+		return rules
+	}()
+)
+
+func applyPodSpecRule(obj map[string]any, rule SortingRule, warn warnFunc) (map[string]any, error) {
+	// without anchor path, find PodSpecs anywhere in the object
+	if rule.Path == "" {
+		patched, err := sortPodSpecs(obj, warn)
+		if err != nil {
+			return nil, err
+		}
+
+		return patched.(map[string]any), nil
+	}
+
+	path, err := rule.JSONPath()
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := jsonpath.Patch(obj, path, func(exists bool, key, val any) (any, error) {
+		if !exists {
+			return nil, nil
+		}
+
+		podSpec, ok := val.(map[string]any)
+		if !ok {
+			return val, nil
+		}
+
+		return sortPodSpec(podSpec, warn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patched.(map[string]any), nil
+}
+
+func sortPodSpecs(val any, warn warnFunc) (any, error) {
+	switch asserted := val.(type) {
+	case map[string]any:
+		if isPodSpec(asserted) {
+			return sortPodSpec(asserted, warn)
+		}
+
+		for key, value := range asserted {
+			sorted, err := sortPodSpecs(value, warn)
+			if err != nil {
+				return nil, err
+			}
+
+			asserted[key] = sorted
+		}
+
+		return asserted, nil
+
+	case []any:
+		for i, item := range asserted {
+			sorted, err := sortPodSpecs(item, warn)
+			if err != nil {
+				return nil, err
+			}
+
+			asserted[i] = sorted
+		}
+
+		return asserted, nil
+
+	default:
+		return val, nil
+	}
+}
+
+func sortPodSpec(podSpec map[string]any, warn warnFunc) (map[string]any, error) {
+	for _, rule := range podSpecRules {
+		patched, err := applyRule(podSpec, rule, warn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sort %s: %w", rule.Path, err)
+		}
+
+		podSpec = patched
+	}
+
+	return podSpec, nil
+}
+
+// isPodSpec recognizes PodSpecs by their structure, as custom resources can
+// embed them anywhere: an object is considered a PodSpec if it has a
+// non-empty list of containers, where every container has a name and only
+// fields that are defined for Containers. The image is not required, as
+// templates (for example for kustomize) often set it later on.
+func isPodSpec(obj map[string]any) bool {
+	containers, ok := obj["containers"].([]any)
+	if !ok || len(containers) == 0 {
+		return false
+	}
+
+	for _, c := range containers {
+		container, ok := c.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		if _, ok := container["name"].(string); !ok {
+			return false
+		}
+
+		for field := range container {
+			if _, ok := containerFields[field]; !ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
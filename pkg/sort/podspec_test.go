@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSortPodSpecs(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "Pod",
+			input: `
+kind: Pod
+spec:
+  containers: [{name: b, image: b}, {name: a, image: a}]
+`,
+			expected: `
+kind: Pod
+spec:
+  containers: [{name: a, image: a}, {name: b, image: b}]
+`,
+		},
+		{
+			name: "Deployment without images",
+			input: `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers: [{name: b}, {name: a, env: [{name: Z}, {name: A}]}]
+`,
+			expected: `
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers: [{name: a, env: [{name: A}, {name: Z}]}, {name: b}]
+`,
+		},
+		{
+			name: "CronJob",
+			input: `
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers: [{name: b, image: b}, {name: a, image: a}]
+          containers: [{name: b, image: b}, {name: a, image: a}]
+          volumes: [{name: b}, {name: a}]
+`,
+			expected: `
+kind: CronJob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers: [{name: b, image: b}, {name: a, image: a}]
+          containers: [{name: a, image: a}, {name: b, image: b}]
+          volumes: [{name: a}, {name: b}]
+`,
+		},
+		{
+			name: "custom resource with embedded PodSpecs",
+			input: `
+kind: Workflow
+spec:
+  steps:
+    - name: build
+      pod:
+        containers: [{name: b, image: b}, {name: a, image: a}]
+`,
+			expected: `
+kind: Workflow
+spec:
+  steps:
+    - name: build
+      pod:
+        containers: [{name: a, image: a}, {name: b, image: b}]
+`,
+		},
+		{
+			name: "containers without names are not a PodSpec",
+			input: `
+kind: Workflow
+spec:
+  containers: [{image: b, volumes: [{name: b}, {name: a}]}, {name: a}]
+  volumes: [{name: b}, {name: a}]
+`,
+			expected: `
+kind: Workflow
+spec:
+  containers: [{image: b, volumes: [{name: b}, {name: a}]}, {name: a}]
+  volumes: [{name: b}, {name: a}]
+`,
+		},
+		{
+			name: "containers with unknown fields are not a PodSpec",
+			input: `
+kind: Registry
+spec:
+  containers: [{name: b, registry: b}, {name: a, registry: a}]
+`,
+			expected: `
+kind: Registry
+spec:
+  containers: [{name: b, registry: b}, {name: a, registry: a}]
+`,
+		},
+		{
+			name: "empty containers are not a PodSpec",
+			input: `
+kind: Registry
+spec:
+  containers: []
+  volumes: [{name: b}, {name: a}]
+`,
+			expected: `
+kind: Registry
+spec:
+  containers: []
+  volumes: [{name: b}, {name: a}]
+`,
+		},
+	}
+
+	rules := []SortingRule{{PodSpec: ptr.To(true)}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(tc.input), &obj.Object); err != nil {
+				t.Fatalf("Failed to decode input: %v", err)
+			}
+
+			expected := map[string]any{}
+			if err := yaml.Unmarshal([]byte(tc.expected), &expected); err != nil {
+				t.Fatalf("Failed to decode expected object: %v", err)
+			}
+
+			sorted, err := Object(obj, rules)
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			if !cmp.Equal(expected, sorted.Object) {
+				t.Fatalf("Unexpected result:\n%s", cmp.Diff(expected, sorted.Object))
+			}
+		})
+	}
+}
+
+func TestSortPodSpecAtPath(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(`{spec: {runner: {containers: [{name: b, custom: true}, {name: a}]}}}`), &obj.Object); err != nil {
+		t.Fatalf("Failed to decode input: %v", err)
+	}
+
+	// an explicit path does not rely on the heuristic
+	sorted, err := Object(obj, []SortingRule{{Path: "spec.runner", PodSpec: ptr.To(true)}})
+	if err != nil {
+		t.Fatalf("Failed to sort: %v", err)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(sorted.Object, "spec", "runner", "containers")
+	if name := containers[0].(map[string]any)["name"]; name != "a" {
+		t.Fatalf("Expected containers to be sorted, but got %v.", containers)
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"fmt"
+	"slices"
+)
+
+type SortOrder string
+
+const (
+	AscendingOrder  SortOrder = "asc"
+	DescendingOrder SortOrder = "desc"
+)
+
+func (o SortOrder) Validate() error {
+	switch o {
+	case "", AscendingOrder, DescendingOrder:
+		return nil
+	default:
+		return fmt.Errorf("unknown order %q, must be %q or %q", o, AscendingOrder, DescendingOrder)
+	}
+}
+
+type PriorityPosition string
+
+const (
+	PriorityFirst PriorityPosition = "first"
+	PriorityLast  PriorityPosition = "last"
+)
+
+func (p PriorityPosition) Validate() error {
+	switch p {
+	case "", PriorityFirst, PriorityLast:
+		return nil
+	default:
+		return fmt.Errorf("unknown priority position %q, must be %q or %q", p, PriorityFirst, PriorityLast)
+	}
+}
+
+type compareFunc func(a, b any) int
+
+// withOrdering wraps a comparison to pin prioritized items to the front (or
+// back) and to reverse the order of all remaining items if needed.
+func withOrdering(compare compareFunc, rule SortingRule, priorityValue func(item any) (any, bool)) compareFunc {
+	if rule.Order == DescendingOrder {
+		ascending := compare
+		compare = func(a, b any) int {
+			return -ascending(a, b)
+		}
+	}
+
+	if len(rule.Priority) == 0 {
+		return compare
+	}
+
+	// items without priority get the lowest priority
+	rank := func(item any) int {
+		value, ok := priorityValue(item)
+		if !ok {
+			return len(rule.Priority)
+		}
+
+		var str string
+		switch value.(type) {
+		case string, bool, int, int32, int64, float32, float64:
+			str = fmt.Sprint(value)
+		default:
+			return len(rule.Priority)
+		}
+
+		if idx := slices.Index(rule.Priority, str); idx >= 0 {
+			return idx
+		}
+
+		return len(rule.Priority)
+	}
+
+	return func(a, b any) int {
+		aRank := rank(a)
+		bRank := rank(b)
+
+		if aRank != bRank {
+			if rule.PriorityPosition == PriorityLast {
+				// shift non-prioritized items (rank = len) before all others
+				aRank = (aRank + 1) % (len(rule.Priority) + 1)
+				bRank = (bRank + 1) % (len(rule.Priority) + 1)
+			}
+
+			if aRank < bRank {
+				return -1
+			}
+
+			return 1
+		}
+
+		return compare(a, b)
+	}
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"fmt"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/jsonpath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type warnFunc func(format string, args ...any)
+
+func (r SortingRule) sortsByValue() bool {
+	return (r.ByValue != nil && *r.ByValue) || (r.Set != nil && *r.Set)
+}
+
+func (r SortingRule) removesDuplicates() bool {
+	return (r.Unique != nil && *r.Unique) || (r.Set != nil && *r.Set)
+}
+
+// removeDuplicates removes duplicates from a sorted list. Items are compared
+// by their keys for byKey rules, where the last item wins (like Kubernetes
+// does for duplicate env vars), or by their entire value otherwise. Items that
+// lack any of the keys are always kept.
+func removeDuplicates(items []any, rule SortingRule) (unique []any, dropped []any, err error) {
+	var keys []jsonpath.Path
+
+	if len(rule.ByKey) > 0 {
+		keys, err = rule.sortKeys()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The rule's comparator cannot be used to find duplicates, as it might
+	// consider values equal that are not (e.g. "1" and "1.0" when comparing
+	// numerically), so the JSON encoding is used as the identity instead.
+	seen := map[string]int{}
+	unique = make([]any, 0, len(items))
+
+	for _, item := range items {
+		identity, ok := itemIdentity(item, keys)
+		if !ok {
+			unique = append(unique, item)
+			continue
+		}
+
+		idx, exists := seen[identity]
+		switch {
+		case !exists:
+			seen[identity] = len(unique)
+			unique = append(unique, item)
+
+		case keys == nil:
+			dropped = append(dropped, item)
+
+		default:
+			dropped = append(dropped, unique[idx])
+			unique[idx] = item
+		}
+	}
+
+	return unique, dropped, nil
+}
+
+func itemIdentity(item any, keys []jsonpath.Path) (string, bool) {
+	if keys == nil {
+		return encodeJSON(item), true
+	}
+
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		value, ok := getField(item, key)
+		if !ok {
+			return "", false
+		}
+
+		values[i] = value
+	}
+
+	return encodeJSON(values), true
+}
+
+func describeItems(items []any) string {
+	descriptions := make([]string, len(items))
+	for i, item := range items {
+		descriptions[i] = encodeJSON(item)
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	name := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		name = fmt.Sprintf("%s/%s", ns, name)
+	}
+
+	return fmt.Sprintf("%s %s", obj.GetKind(), name)
+}
@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package sort
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/utils/ptr"
+)
+
+func TestRemoveDuplicates(t *testing.T) {
+	env := func(name, value string) map[string]any {
+		return map[string]any{"name": name, "value": value}
+	}
+
+	testcases := []struct {
+		name            string
+		rule            SortingRule
+		input           []any
+		expected        []any
+		expectedDropped []any
+	}{
+		{
+			name:            "set",
+			rule:            SortingRule{Set: ptr.To(true)},
+			input:           []any{"get", "list", "get", "watch", "list"},
+			expected:        []any{"get", "list", "watch"},
+			expectedDropped: []any{"get", "list"},
+		},
+		{
+			name:     "no duplicates",
+			rule:     SortingRule{ByValue: ptr.To(true), Unique: ptr.To(true)},
+			input:    []any{"b", "a"},
+			expected: []any{"a", "b"},
+		},
+		{
+			name:            "exact duplicates of different types",
+			rule:            SortingRule{ByValue: ptr.To(true), Unique: ptr.To(true)},
+			input:           []any{int64(1), "1", 1.0, map[string]any{"a": "b"}, map[string]any{"a": "b"}},
+			expected:        []any{int64(1), "1", map[string]any{"a": "b"}},
+			expectedDropped: []any{1.0, map[string]any{"a": "b"}},
+		},
+		{
+			name:            "the comparator does not define equality",
+			rule:            SortingRule{Set: ptr.To(true), Comparator: NumericComparator},
+			input:           []any{"1", "1.0", "1"},
+			expected:        []any{"1", "1.0"},
+			expectedDropped: []any{"1"},
+		},
+		{
+			name:            "last item with the same key wins",
+			rule:            SortingRule{ByKey: SortKeys{"name"}, Unique: ptr.To(true)},
+			input:           []any{env("B", "1"), env("A", "1"), env("B", "2")},
+			expected:        []any{env("A", "1"), env("B", "2")},
+			expectedDropped: []any{env("B", "1")},
+		},
+		{
+			name:     "items without key are kept",
+			rule:     SortingRule{ByKey: SortKeys{"name"}, Unique: ptr.To(true)},
+			input:    []any{map[string]any{"value": "x"}, map[string]any{"value": "x"}, env("A", "1")},
+			expected: []any{map[string]any{"value": "x"}, map[string]any{"value": "x"}, env("A", "1")},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, err := sortSlice(tc.input, tc.rule)
+			if err != nil {
+				t.Fatalf("Failed to sort: %v", err)
+			}
+
+			result, dropped, err := removeDuplicates(sorted, tc.rule)
+			if err != nil {
+				t.Fatalf("Failed to remove duplicates: %v", err)
+			}
+
+			if !cmp.Equal(tc.expected, result) {
+				t.Errorf("Expected %v, but got %v", tc.expected, result)
+			}
+
+			if !cmp.Equal(tc.expectedDropped, dropped) {
+				t.Errorf("Expected to drop %v, but dropped %v", tc.expectedDropped, dropped)
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package types
 
 import (
+	"fmt"
 	"os"
+	"slices"
 
+	"go.xrstf.de/kubesort/pkg/duplicates"
+	"go.xrstf.de/kubesort/pkg/fieldorder"
+	"go.xrstf.de/kubesort/pkg/normalize"
+	"go.xrstf.de/kubesort/pkg/scope"
 	"go.xrstf.de/kubesort/pkg/sort"
 	"gopkg.in/yaml.v3"
 )
@@ -11,12 +17,67 @@ type Configuration struct {
 	FlattenLists              bool               `yaml:"flattenLists"`
 	ObjectRules               []sort.SortingRule `yaml:"objectRules"`
 	DisableDefaultObjectRules bool               `yaml:"disableDefaultObjectRules"`
+	DisableCRDRules           bool               `yaml:"disableCRDRules"`
+	ObjectOrder               sort.ObjectOrder   `yaml:"objectOrder"`
+	// Scopes is used for custom resources whose CRDs are not part of the input.
+	Scopes scope.Table `yaml:"scopes"`
+	// Namespace is set on all namespaced objects without a namespace.
+	Namespace string `yaml:"namespace"`
+	// StripNamespace removes the Namespace again after sorting.
+	StripNamespace bool `yaml:"stripNamespace"`
+	// Duplicates decides how objects with the same GVK, namespace and name
+	// are handled.
+	Duplicates duplicates.Policy `yaml:"duplicates"`
+	// FieldOrder orders the fields inside objects like in the Go types,
+	// instead of alphabetically.
+	FieldOrder fieldorder.Order `yaml:"fieldOrder"`
+	// Normalize lists presets of fields that are removed from all objects
+	// before sorting.
+	Normalize []normalize.Preset `yaml:"normalize"`
+	// RemoveRules remove fields from all matching objects before sorting.
+	RemoveRules []normalize.Rule `yaml:"removeRules"`
+	// ReplaceRules replace fields in all matching objects before sorting.
+	ReplaceRules []normalize.ReplaceRule `yaml:"replaceRules"`
 }
 
 func (c *Configuration) Validate() error {
-	for _, rule := range c.ObjectRules {
+	for i, rule := range c.ObjectRules {
 		if err := rule.Validate(); err != nil {
-			return err
+			return fmt.Errorf("invalid object rule %d: %w", i+1, err)
+		}
+	}
+
+	if err := c.ObjectOrder.Validate(); err != nil {
+		return fmt.Errorf("invalid object order: %w", err)
+	}
+
+	if err := c.Scopes.Validate(); err != nil {
+		return fmt.Errorf("invalid scopes: %w", err)
+	}
+
+	if err := c.Duplicates.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.FieldOrder.Validate(); err != nil {
+		return fmt.Errorf("invalid field order: %w", err)
+	}
+
+	for _, preset := range c.Normalize {
+		if err := preset.Validate(); err != nil {
+			return fmt.Errorf("invalid normalize preset: %w", err)
+		}
+	}
+
+	for i, rule := range c.RemoveRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid remove rule %d: %w", i+1, err)
+		}
+	}
+
+	for i, rule := range c.ReplaceRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid replace rule %d: %w", i+1, err)
 		}
 	}
 
@@ -43,7 +104,14 @@ func LoadConfig(filename string) (*Configuration, error) {
 
 	// prepend the default rules
 	if !cfg.DisableDefaultObjectRules {
-		cfg.ObjectRules = append(defaultObjectRules, cfg.ObjectRules...)
+		cfg.ObjectRules = append(slices.Clone(defaultObjectRules), cfg.ObjectRules...)
+	}
+
+	// parse all paths once instead of every time a rule is applied
+	for i := range cfg.ObjectRules {
+		if err := cfg.ObjectRules[i].Compile(); err != nil {
+			return nil, fmt.Errorf("invalid object rule %d: %w", i+1, err)
+		}
 	}
 
 	return cfg, nil
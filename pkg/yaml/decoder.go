@@ -10,6 +10,8 @@ import (
 	"io"
 	"os"
 
+	yamlv3 "gopkg.in/yaml.v3"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	yamlutil "k8s.io/apimachinery/pkg/util/yaml"
 )
@@ -19,7 +21,30 @@ const (
 	bufSize = 5 * 1024 * 1024
 )
 
-func Decode(source string) ([]*unstructured.Unstructured, error) {
+// Document is a single object decoded from a YAML stream.
+type Document struct {
+	Object *unstructured.Unstructured
+	// Source is the filename the object was read from ("-" for stdin).
+	Source string
+	// Index is the 1-based position of the YAML document in its source.
+	Index int
+	// Node is the document as parsed by yaml.v3, including all comments. It
+	// is nil if the document could not be parsed by yaml.v3.
+	Node *yamlv3.Node
+}
+
+func (d Document) String() string {
+	source := d.Source
+	if source == "-" {
+		source = "stdin"
+	}
+
+	return fmt.Sprintf("%s (document %d)", source, d.Index)
+}
+
+// Decode reads all documents from a file, stdin ("-") or all YAML and JSON
+// files in a directory (see ExpandSources).
+func Decode(source string) ([]Document, error) {
 	if source == "-" {
 		// thank you https://stackoverflow.com/a/26567513
 		stat, _ := os.Stdin.Stat()
@@ -27,7 +52,7 @@ func Decode(source string) ([]*unstructured.Unstructured, error) {
 			return nil, errors.New("no data provided on stdin")
 		}
 
-		return DecodeReader(os.Stdin)
+		return DecodeReader(os.Stdin, source)
 	}
 
 	stat, err := os.Stat(source)
@@ -36,30 +61,47 @@ func Decode(source string) ([]*unstructured.Unstructured, error) {
 	}
 
 	if stat.IsDir() {
-		return nil, fmt.Errorf("%s is a directory", source)
+		files, err := findFiles(source, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		documents := []Document{}
+		for _, file := range files {
+			fileDocuments, err := DecodeFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+			}
+
+			documents = append(documents, fileDocuments...)
+		}
+
+		return documents, nil
 	}
 
 	return DecodeFile(source)
 }
 
-func DecodeFile(source string) ([]*unstructured.Unstructured, error) {
+func DecodeFile(source string) ([]Document, error) {
 	f, err := os.Open(source)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer f.Close()
 
-	return DecodeReader(f)
+	return DecodeReader(f, source)
 }
 
-func DecodeReader(source io.ReadCloser) ([]*unstructured.Unstructured, error) {
+func DecodeReader(source io.ReadCloser, name string) ([]Document, error) {
 	docSplitter := yamlutil.NewDocumentDecoder(source)
 	defer docSplitter.Close()
 
-	result := []*unstructured.Unstructured{}
+	result := []Document{}
+
+	// the buffer can be reused, as every document is decoded into new values
+	buf := make([]byte, bufSize)
 
 	for i := 1; true; i++ {
-		buf := make([]byte, bufSize)
 		read, err := docSplitter.Read(buf)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
@@ -82,12 +124,26 @@ func DecodeReader(source io.ReadCloser) ([]*unstructured.Unstructured, error) {
 			continue
 		}
 
-		result = append(result, object)
+		result = append(result, Document{
+			Object: object,
+			Source: name,
+			Index:  i,
+			Node:   parseNode(buf[:read]),
+		})
 	}
 
 	return result, nil
 }
 
+func parseNode(data []byte) *yamlv3.Node {
+	node := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, node); err != nil || node.Kind != yamlv3.DocumentNode {
+		return nil
+	}
+
+	return node
+}
+
 func parseDocument(data []byte) (*unstructured.Unstructured, error) {
 	obj := &unstructured.Unstructured{}
 
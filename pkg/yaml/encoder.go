@@ -4,6 +4,9 @@
 package yaml
 
 import (
+	"bytes"
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	sigsyaml "sigs.k8s.io/yaml"
 )
@@ -11,3 +14,19 @@ import (
 func Encode(obj *unstructured.Unstructured) ([]byte, error) {
 	return sigsyaml.Marshal(obj)
 }
+
+// EncodeAll encodes all objects into a single multi-document YAML stream.
+func EncodeAll(objects []*unstructured.Unstructured) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, obj := range objects {
+		encoded, err := Encode(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&buf, "---\n%s\n", encoded)
+	}
+
+	return buf.Bytes(), nil
+}
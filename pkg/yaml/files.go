@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yaml
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// fileExtensions are the files that are picked up in directories.
+var fileExtensions = []string{".yaml", ".yml", ".json"}
+
+// ExpandSources turns directories and glob patterns into a list of files.
+// Directories are searched recursively for YAML and JSON files, skipping
+// hidden files and directories. Files and directories found this way that
+// match any of the exclude patterns (matched against both their path and
+// their name) are skipped; explicitly given paths are never excluded. Files
+// are returned in lexical order per source and only once.
+func ExpandSources(sources []string, excludes []string) ([]string, error) {
+	for _, pattern := range excludes {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	result := []string{}
+
+	add := func(filename string) {
+		if !slices.Contains(result, filename) {
+			result = append(result, filename)
+		}
+	}
+
+	for _, source := range sources {
+		if source == "-" {
+			add(source)
+			continue
+		}
+
+		paths := []string{source}
+
+		if isGlob(source) {
+			matches, err := filepath.Glob(source)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", source, err)
+			}
+
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("pattern %q did not match any files", source)
+			}
+
+			// Glob already returns the matches sorted
+			paths = matches
+		}
+
+		for _, path := range paths {
+			if isGlob(source) && isExcluded(path, excludes) {
+				continue
+			}
+
+			stat, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid source: %w", err)
+			}
+
+			if !stat.IsDir() {
+				add(path)
+				continue
+			}
+
+			files, err := findFiles(path, excludes)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, file := range files {
+				add(file)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func findFiles(root string, excludes []string) ([]string, error) {
+	files := []string{}
+
+	// WalkDir walks in lexical order, so the result is deterministic
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != root && (strings.HasPrefix(d.Name(), ".") || isExcluded(path, excludes)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() && IsManifestFile(path) {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// IsManifestFile returns true for files that are picked up in directories.
+func IsManifestFile(filename string) bool {
+	return slices.Contains(fileExtensions, strings.ToLower(filepath.Ext(filename)))
+}
+
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+func isExcluded(path string, excludes []string) bool {
+	for _, pattern := range excludes {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandSources(t *testing.T) {
+	root := t.TempDir()
+
+	for _, file := range []string{
+		"b/deployment.yaml",
+		"b/service.yml",
+		"a/config.json",
+		"a/README.md",
+		"a/nested/secret.yaml",
+		"a/.hidden/ignored.yaml",
+		"vendor/chart.yaml",
+		"top.yaml",
+	} {
+		filename := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		sources  []string
+		excludes []string
+		expected []string
+	}{
+		{
+			name:    "directory",
+			sources: []string{root},
+			expected: []string{
+				"a/config.json",
+				"a/nested/secret.yaml",
+				"b/deployment.yaml",
+				"b/service.yml",
+				"top.yaml",
+				"vendor/chart.yaml",
+			},
+		},
+		{
+			name:     "exclude directories and files by name",
+			sources:  []string{root},
+			excludes: []string{"vendor", "*.yml"},
+			expected: []string{
+				"a/config.json",
+				"a/nested/secret.yaml",
+				"b/deployment.yaml",
+				"top.yaml",
+			},
+		},
+		{
+			name:     "exclude by path",
+			sources:  []string{root},
+			excludes: []string{filepath.Join(root, "a", "*")},
+			expected: []string{
+				"b/deployment.yaml",
+				"b/service.yml",
+				"top.yaml",
+				"vendor/chart.yaml",
+			},
+		},
+		{
+			name:     "exclude glob matches",
+			sources:  []string{filepath.Join(root, "*", "*.y*ml")},
+			excludes: []string{filepath.Join(root, "vendor", "*"), "*.yml"},
+			expected: []string{
+				"b/deployment.yaml",
+			},
+		},
+		{
+			name:     "explicit paths are not excluded",
+			sources:  []string{filepath.Join(root, "b", "service.yml"), filepath.Join(root, "vendor"), filepath.Join(root, "b")},
+			excludes: []string{"vendor", "*.yml"},
+			expected: []string{
+				"b/service.yml",
+				"vendor/chart.yaml",
+				"b/deployment.yaml",
+			},
+		},
+		{
+			name:    "glob",
+			sources: []string{filepath.Join(root, "*", "*.y*ml"), filepath.Join(root, "a", "README.md")},
+			expected: []string{
+				"b/deployment.yaml",
+				"b/service.yml",
+				"vendor/chart.yaml",
+				"a/README.md",
+			},
+		},
+		{
+			name:    "files are only returned once and stdin is kept",
+			sources: []string{filepath.Join(root, "top.yaml"), "-", filepath.Join(root, "b")},
+			expected: []string{
+				"top.yaml",
+				"-",
+				"b/deployment.yaml",
+				"b/service.yml",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := ExpandSources(append(tc.sources, tc.sources...), tc.excludes)
+			if err != nil {
+				t.Fatalf("Failed to expand sources: %v", err)
+			}
+
+			for i, file := range files {
+				if file != "-" {
+					files[i], _ = filepath.Rel(root, file)
+				}
+			}
+
+			for i, file := range tc.expected {
+				tc.expected[i] = filepath.FromSlash(file)
+			}
+
+			if !cmp.Equal(tc.expected, files) {
+				t.Fatalf("Unexpected files:\n%s", cmp.Diff(tc.expected, files))
+			}
+		})
+	}
+}
+
+func TestExpandSourcesErrors(t *testing.T) {
+	root := t.TempDir()
+
+	testcases := []struct {
+		name     string
+		sources  []string
+		excludes []string
+	}{
+		{
+			name:    "missing file",
+			sources: []string{filepath.Join(root, "missing.yaml")},
+		},
+		{
+			name:    "glob without matches",
+			sources: []string{filepath.Join(root, "*.yaml")},
+		},
+		{
+			name:     "invalid exclude pattern",
+			sources:  []string{root},
+			excludes: []string{"["},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ExpandSources(tc.sources, tc.excludes); err == nil {
+				t.Fatal("Expected an error, but got none.")
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MergeKey is YAML's "<<" key to merge other mappings into a mapping.
+const MergeKey = "<<"
+
+// ListItem returns the document for the i-th item of the List in this
+// document. The item's node is used when preserving comments.
+func (d Document) ListItem(obj *unstructured.Unstructured, i int) Document {
+	item := Document{
+		Object: obj,
+		Source: d.Source,
+		Index:  d.Index,
+	}
+
+	if d.Node != nil && len(d.Node.Content) > 0 {
+		if items := mappingValue(d.Node.Content[0], "items"); items != nil && items.Kind == yamlv3.SequenceNode && i < len(items.Content) {
+			item.Node = &yamlv3.Node{
+				Kind:    yamlv3.DocumentNode,
+				Content: []*yamlv3.Node{items.Content[i]},
+			}
+		}
+	}
+
+	return item
+}
+
+// NodeFunc can modify an object's node right before it is encoded.
+type NodeFunc func(obj *unstructured.Unstructured, node *yamlv3.Node)
+
+// EncodePreserving encodes all documents into a single multi-document YAML
+// stream, like EncodeAll. Instead of encoding each object from scratch, the
+// document's original node is updated to match the object, so comments,
+// anchors, quoting and the order of keys in mappings are kept wherever the
+// content did not change. Indentation is not kept: yaml.v3 always indents
+// block sequences under their key (and has no option to change that).
+// Documents without a node are encoded like in EncodeAll, unless a NodeFunc
+// is given, which is then called for every document.
+func EncodePreserving(documents []Document, fn NodeFunc) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, doc := range documents {
+		var root *yamlv3.Node
+
+		if doc.Node == nil || len(doc.Node.Content) == 0 {
+			if fn == nil {
+				encoded, err := Encode(doc.Object)
+				if err != nil {
+					return nil, err
+				}
+
+				fmt.Fprintf(&buf, "---\n%s\n", encoded)
+				continue
+			}
+
+			node, err := encodeNode(doc.Object.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", doc, err)
+			}
+
+			root = node
+			doc.Node = &yamlv3.Node{Kind: yamlv3.DocumentNode}
+		} else {
+			node, err := patchNode(doc.Node.Content[0], doc.Object.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update %s: %w", doc, err)
+			}
+
+			root = node
+		}
+
+		if fn != nil {
+			fn(doc.Object, root)
+		}
+
+		doc.Node.Content = []*yamlv3.Node{root}
+		resetMergeKeys(root)
+
+		var docBuf bytes.Buffer
+
+		encoder := yamlv3.NewEncoder(&docBuf)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(doc.Node); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", doc, err)
+		}
+
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", doc, err)
+		}
+
+		fmt.Fprintf(&buf, "---\n%s\n", docBuf.Bytes())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// patchNode updates the node to represent the value and returns it. Nodes
+// that cannot be updated are replaced, keeping their comments.
+func patchNode(node *yamlv3.Node, value any) (*yamlv3.Node, error) {
+	if equalValue(node, value) {
+		return node, nil
+	}
+
+	switch asserted := value.(type) {
+	case map[string]any:
+		if node.Kind == yamlv3.MappingNode {
+			return patchMapping(node, asserted)
+		}
+
+	case []any:
+		if node.Kind == yamlv3.SequenceNode {
+			return patchSequence(node, asserted)
+		}
+	}
+
+	replacement, err := encodeNode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	replacement.HeadComment = node.HeadComment
+	replacement.LineComment = node.LineComment
+	replacement.FootComment = node.FootComment
+
+	// keep quoted or block scalars quoted or block scalars
+	if node.Kind == yamlv3.ScalarNode && replacement.Kind == yamlv3.ScalarNode && node.Tag == replacement.Tag {
+		replacement.Style = node.Style
+	}
+
+	return replacement, nil
+}
+
+func patchMapping(node *yamlv3.Node, value map[string]any) (*yamlv3.Node, error) {
+	content := make([]*yamlv3.Node, 0, len(node.Content))
+	existing := map[string]struct{}{}
+	merged := map[string]any{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		if keyNode.Kind != yamlv3.ScalarNode {
+			content = append(content, keyNode, valueNode)
+			continue
+		}
+
+		// merged keys are kept and can be overwritten by explicit keys below
+		if keyNode.Value == MergeKey {
+			if err := collectMergedKeys(valueNode, merged); err != nil {
+				return nil, err
+			}
+
+			content = append(content, keyNode, valueNode)
+			continue
+		}
+
+		fieldValue, exists := value[keyNode.Value]
+		if !exists {
+			continue
+		}
+
+		patched, err := patchNode(valueNode, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, keyNode, patched)
+		existing[keyNode.Value] = struct{}{}
+	}
+
+	// new keys are appended in alphabetical order
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if _, exists := existing[key]; exists {
+			continue
+		}
+
+		if mergedValue, exists := merged[key]; exists && equalJSON(mergedValue, value[key]) {
+			continue
+		}
+
+		keyNode, err := encodeNode(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueNode, err := encodeNode(value[key])
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, keyNode, valueNode)
+	}
+
+	node.Content = content
+
+	return node, nil
+}
+
+// patchSequence reorders the items to match the list. Items are paired with
+// their counterpart by content, ignoring the order of nested lists (which
+// might have been sorted as well). Items without an identical counterpart
+// are paired in their original order, items that are left over are dropped.
+func patchSequence(node *yamlv3.Node, value []any) (*yamlv3.Node, error) {
+	itemKeys := make([]string, len(node.Content))
+	for i, item := range node.Content {
+		var decoded any
+		if err := item.Decode(&decoded); err == nil {
+			itemKeys[i] = canonicalKey(decoded)
+		}
+	}
+
+	used := make([]bool, len(node.Content))
+	paired := make([]*yamlv3.Node, len(value))
+
+	for i, item := range value {
+		key := canonicalKey(item)
+
+		for j, itemKey := range itemKeys {
+			if !used[j] && itemKey != "" && itemKey == key {
+				used[j] = true
+				paired[i] = node.Content[j]
+				break
+			}
+		}
+	}
+
+	for i := range value {
+		if paired[i] != nil {
+			continue
+		}
+
+		if j := slices.Index(used, false); j >= 0 {
+			used[j] = true
+			paired[i] = node.Content[j]
+		}
+	}
+
+	content := make([]*yamlv3.Node, len(value))
+
+	for i, item := range value {
+		if paired[i] == nil {
+			encoded, err := encodeNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			content[i] = encoded
+			continue
+		}
+
+		patched, err := patchNode(paired[i], item)
+		if err != nil {
+			return nil, err
+		}
+
+		content[i] = patched
+	}
+
+	node.Content = content
+
+	return node, nil
+}
+
+func collectMergedKeys(node *yamlv3.Node, merged map[string]any) error {
+	var decoded any
+	if err := node.Decode(&decoded); err != nil {
+		return fmt.Errorf("invalid merge key: %w", err)
+	}
+
+	// a single mapping or a list of mappings, where earlier mappings win
+	sources := []any{decoded}
+	if list, ok := decoded.([]any); ok {
+		sources = list
+		slices.Reverse(sources)
+	}
+
+	for _, source := range sources {
+		if mapping, ok := source.(map[string]any); ok {
+			for key, value := range mapping {
+				merged[key] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// resetMergeKeys removes the tag from all merge keys, as yaml.v3 would
+// otherwise print them as "!!merge <<".
+func resetMergeKeys(node *yamlv3.Node) {
+	if node.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; key.Kind == yamlv3.ScalarNode && key.Value == MergeKey {
+				key.Tag = ""
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		resetMergeKeys(child)
+	}
+}
+
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Kind == yamlv3.ScalarNode && node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func encodeNode(value any) (*yamlv3.Node, error) {
+	node := &yamlv3.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// equalValue compares the node's content with the value. Both are compared
+// by their JSON encoding, because numbers are decoded into different types
+// by yaml.v3 and the YAML decoder used for objects.
+func equalValue(node *yamlv3.Node, value any) bool {
+	// yaml.v3 decodes timestamps as time.Time, but objects keep them as strings
+	if node.Kind == yamlv3.ScalarNode && node.ShortTag() == "!!timestamp" {
+		str, ok := value.(string)
+		return ok && str == node.Value
+	}
+
+	var decoded any
+	if err := node.Decode(&decoded); err != nil {
+		return false
+	}
+
+	return equalJSON(decoded, value)
+}
+
+func equalJSON(a, b any) bool {
+	aEncoded, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	bEncoded, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(aEncoded, bEncoded)
+}
+
+// canonicalKey encodes the value with all nested lists sorted and without
+// duplicates, so it identifies an item regardless of how the lists inside of
+// it were sorted. An empty string is returned for values that cannot be
+// encoded.
+func canonicalKey(value any) string {
+	encoded, err := json.Marshal(canonicalize(value))
+	if err != nil {
+		return ""
+	}
+
+	return string(encoded)
+}
+
+func canonicalize(value any) any {
+	switch asserted := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(asserted))
+		for key, field := range asserted {
+			result[key] = canonicalize(field)
+		}
+
+		return result
+
+	case []any:
+		items := make([]string, 0, len(asserted))
+		for _, item := range asserted {
+			items = append(items, canonicalKey(item))
+		}
+
+		slices.Sort(items)
+
+		return slices.Compact(items)
+
+	default:
+		return value
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package yaml
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEncodePreserving(t *testing.T) {
+	input := `# the config
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test # line comment
+  labels:
+    removed: "true"
+data:
+  quoted: "1"
+  literal: |
+    multi
+    line
+  date: 2024-01-01
+list:
+  # about b
+  - name: b
+    values: [3, 1, 2]
+  # about a
+  - name: a
+  - name: a
+base: &base
+  foo: bar
+merged:
+  <<: *base
+  own: value
+`
+
+	expected := `---
+# the config
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test # line comment
+  namespace: default
+data:
+  quoted: "2"
+  literal: |
+    multi
+    line
+  date: 2024-01-01
+list:
+  # about a
+  - name: a
+  # about b
+  - name: b
+    values: [1, 2, 3]
+base: &base
+  foo: bar
+merged:
+  <<: *base
+  own: value
+
+`
+
+	documents, err := DecodeReader(io.NopCloser(strings.NewReader(input)), "test.yaml")
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	obj := documents[0].Object
+	obj.SetNamespace("default")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "labels")
+	obj.Object["data"].(map[string]any)["quoted"] = "2"
+	obj.Object["list"] = []any{
+		map[string]any{"name": "a"},
+		map[string]any{"name": "b", "values": []any{int64(1), int64(2), int64(3)}},
+	}
+
+	encoded, err := EncodePreserving(documents, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	if string(encoded) != expected {
+		t.Fatalf("Expected\n%s\nbut got\n%s", expected, encoded)
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.xrstf.de/kubesort/pkg/output"
+	"go.xrstf.de/kubesort/pkg/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// rename can be replaced in tests to simulate errors.
+var rename = os.Rename
+
+// runWrite sorts each file on its own and writes it back. If preserve is set,
+// comments are kept in YAML files.
+func runWrite(files []string, config *types.Configuration, preserve bool) {
+	for _, file := range files {
+		if file == "-" {
+			fatal("Cannot write to stdin.")
+		}
+	}
+
+	for _, file := range files {
+		objects, objectSources := sortFiles([]string{file}, config)
+
+		encoded, err := encodeFile(file, objects, objectSources, config, preserve)
+		if err != nil {
+			fatalf("Failed to encode %q: %v", file, err)
+		}
+
+		if err := writeFile(file, encoded); err != nil {
+			fatalf("Failed to write %q: %v", file, err)
+		}
+	}
+}
+
+// encodeFile encodes the objects in the format of the file they were loaded
+// from. JSON files can only hold a single object, all other files are YAML.
+func encodeFile(file string, objects []*unstructured.Unstructured, objectSources sources, config *types.Configuration, preserve bool) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		if len(objects) != 1 {
+			return nil, fmt.Errorf("JSON files must contain exactly one object, but found %d", len(objects))
+		}
+
+		return output.EncodeJSONObject(objects[0])
+	}
+
+	return objectSources.encode(objects, config, preserve)
+}
+
+// writeFile replaces the file atomically by writing to a temporary file in
+// the same directory and renaming it. The original file permissions are
+// kept; files that would not change are not touched at all.
+func writeFile(filename string, content []byte) error {
+	// replace the target of symlinks, not the links themselves
+	filename, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return err
+	}
+
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(current, content) {
+		return nil
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(filename), fmt.Sprintf(".%s.*", filepath.Base(filename)))
+	if err != nil {
+		return err
+	}
+
+	// this is a nop once the file has been renamed
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Chmod(stat.Mode().Perm()); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return rename(tmpFile.Name(), filename)
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2024 Christoph Mewes
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.xrstf.de/kubesort/pkg/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWriteFile(t *testing.T) {
+	filename := writeTestFile(t, "old")
+
+	if err := os.Chmod(filename, 0600); err != nil {
+		t.Fatalf("Failed to change mode: %v", err)
+	}
+
+	before, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if err := writeFile(filename, []byte("new")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	after, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	// the file is replaced by renaming, not truncated and rewritten
+	if os.SameFile(before, after) {
+		t.Error("Expected the file to be replaced.")
+	}
+
+	if after.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, but got %v.", after.Mode().Perm())
+	}
+
+	assertFileContent(t, filename, "new")
+	assertNoTempFiles(t, filepath.Dir(filename))
+}
+
+func TestWriteFileUnchanged(t *testing.T) {
+	filename := writeTestFile(t, "same")
+
+	before, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if err := writeFile(filename, []byte("same")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	after, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if !os.SameFile(before, after) {
+		t.Error("Expected the file not to be touched.")
+	}
+}
+
+func TestWriteFileSymlink(t *testing.T) {
+	filename := writeTestFile(t, "old")
+
+	link := filepath.Join(t.TempDir(), "link.yaml")
+	if err := os.Symlink(filename, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if err := writeFile(link, []byte("new")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	stat, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Failed to stat symlink: %v", err)
+	}
+
+	if stat.Mode()&os.ModeSymlink == 0 {
+		t.Error("Expected the symlink to be kept.")
+	}
+
+	assertFileContent(t, filename, "new")
+	assertNoTempFiles(t, filepath.Dir(filename))
+}
+
+func TestWriteFileCleanup(t *testing.T) {
+	filename := writeTestFile(t, "old")
+
+	rename = func(string, string) error {
+		return errors.New("rename failed")
+	}
+	defer func() {
+		rename = os.Rename
+	}()
+
+	if err := writeFile(filename, []byte("new")); err == nil {
+		t.Fatal("Expected an error.")
+	}
+
+	assertFileContent(t, filename, "old")
+	assertNoTempFiles(t, filepath.Dir(filename))
+}
+
+func TestEncodeFile(t *testing.T) {
+	config, err := types.LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load default configuration: %v", err)
+	}
+
+	testcases := []struct {
+		filename string
+		input    string
+		expected string
+	}{
+		{
+			filename: "cm.yaml",
+			input:    `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"a"}}`,
+			expected: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n\n",
+		},
+		{
+			filename: "cm.json",
+			input:    `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"a"}}`,
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"ConfigMap\",\n  \"metadata\": {\n    \"name\": \"a\"\n  }\n}\n",
+		},
+		{
+			filename: "CM.JSON",
+			input:    `{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"a"}}`,
+			expected: "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"ConfigMap\",\n  \"metadata\": {\n    \"name\": \"a\"\n  }\n}\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.filename, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tc.filename)
+			if err := os.WriteFile(filename, []byte(tc.input), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			objects, objectSources := loadFiles([]string{filename})
+
+			encoded, err := encodeFile(filename, objects, objectSources, config, false)
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			if string(encoded) != tc.expected {
+				t.Errorf("Expected\n%s\nbut got\n%s", tc.expected, string(encoded))
+			}
+		})
+	}
+}
+
+func TestEncodeFileMultipleJSONObjects(t *testing.T) {
+	objects := []*unstructured.Unstructured{{}, {}}
+
+	if _, err := encodeFile("list.json", objects, sources{}, &types.Configuration{}, false); err == nil {
+		t.Fatal("Should not have encoded multiple objects into a JSON file.")
+	}
+}
+
+func assertFileContent(t *testing.T, filename string, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	if string(content) != expected {
+		t.Errorf("Expected file to contain %q, but got %q.", expected, string(content))
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() != "manifest.yaml" {
+			t.Errorf("Unexpected file %s left behind.", entry.Name())
+		}
+	}
+}